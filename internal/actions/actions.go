@@ -0,0 +1,313 @@
+// Package actions implements what can be done with a saved screenshot.
+// The CLI, the TUI, notifications and Tarragon all go through it.
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"orego/internal/apprules"
+	"orego/internal/config"
+	"orego/internal/db"
+	"orego/internal/imaging"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+// Action names, as used by Tarragon and notification buttons.
+const (
+	Open         = "open"
+	Copy         = "copy"
+	CopyPath     = "copy-path"
+	OpenFolder   = "open-folder"
+	OCRCopy      = "ocr-copy"
+	ShowMetadata = "show-metadata"
+	Delete       = "delete"
+)
+
+// Result is the outcome of a successful action.
+type Result struct {
+	Action   string             `json:"action"`
+	Message  string             `json:"message"`
+	Text     string             `json:"text,omitempty"`     // ocr-copy
+	Metadata *models.Screenshot `json:"metadata,omitempty"` // show-metadata
+}
+
+// Env is what actions need besides the screenshot.
+type Env struct {
+	Store  *db.Store
+	Config config.Config
+}
+
+// Available returns the actions that apply to sc, in display order.
+// Actions that need the file are left out when it is gone or cannot be
+// decrypted, and ocr-copy only applies to images.
+func Available(sc *models.Screenshot, cfg config.Config) []string {
+	names := make([]string, 0, 7)
+	readable := Readable(sc.FilePath)
+	if readable {
+		names = append(names, Open, Copy)
+	}
+	names = append(names, CopyPath)
+	if exists(sc.FilePath) {
+		names = append(names, OpenFolder)
+	}
+	if readable && !sc.IsVideo() && cfg.Capture.OCR.Cmd != "" {
+		if _, err := exec.LookPath(cfg.Capture.OCR.Cmd); err == nil {
+			names = append(names, OCRCopy)
+		}
+	}
+	return append(names, ShowMetadata, Delete)
+}
+
+// Run performs the named action on sc.
+func Run(name string, env Env, sc *models.Screenshot) (Result, error) {
+	res := Result{Action: name}
+	var err error
+	switch name {
+	case Open:
+		err = OpenFile(env.Config, sc.FilePath)
+		res.Message = "Opened " + sc.FilePath
+	case Copy:
+		err = CopyImage(env.Config, sc.FilePath, MimeType(sc))
+		res.Message = "Copied image to clipboard"
+	case CopyPath:
+		err = CopyText(env.Config, sc.FilePath)
+		res.Message = "Copied path to clipboard"
+	case OpenFolder:
+		err = OpenFolderOf(env.Config, sc.FilePath)
+		res.Message = "Opened " + filepath.Dir(sc.FilePath)
+	case OCRCopy:
+		if sc.IsVideo() {
+			return res, fmt.Errorf("recordings cannot be OCRed")
+		}
+		// OCR args may be tuned per app by the screenshot's app rule.
+		res.Text, err = OCR(apprules.ForScreenshot(env.Config, sc).Capture.OCR, sc)
+		if err == nil {
+			err = CopyText(env.Config, res.Text)
+		}
+		res.Message = "Copied text to clipboard"
+	case ShowMetadata:
+		res.Metadata = sc
+		res.Message = "Metadata of screenshot " + fmt.Sprint(sc.ID)
+	case Delete:
+		err = env.Store.DeleteScreenshot(sc.ID)
+		res.Message = fmt.Sprintf("Deleted screenshot %d", sc.ID)
+	default:
+		return res, fmt.Errorf("unsupported action %q", name)
+	}
+	if err != nil {
+		return Result{Action: name}, err
+	}
+	return res, nil
+}
+
+// Readable reports whether the file exists and, if it is encrypted,
+// whether the vault is unlocked.
+func Readable(path string) bool {
+	if !exists(path) {
+		return false
+	}
+	if !vault.IsEncrypted(path) {
+		return true
+	}
+	v, err := vault.Current()
+	return err == nil && v != nil && v.Unlocked()
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// MimeType returns the stored MIME type, guessing from the file name for
+// entries saved before it was recorded.
+func MimeType(sc *models.Screenshot) string {
+	if sc.MimeType != "" {
+		return sc.MimeType
+	}
+	return imaging.MimeType(vault.PlainName(sc.FilePath))
+}
+
+// command renders the templates of cmd.
+func command(cmd config.CommandConfig, data config.TemplateData) (*exec.Cmd, error) {
+	if cmd.Cmd == "" {
+		return nil, fmt.Errorf("no command configured")
+	}
+	args, err := config.RenderArgs(cmd.Args, data)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(cmd.Cmd, args...), nil
+}
+
+// OpenFile opens a (possibly encrypted) file with the viewer command.
+func OpenFile(cfg config.Config, path string) error {
+	if err := checkExists(path); err != nil {
+		return err
+	}
+	plainPath, err := vault.PlainPath(path)
+	if err != nil {
+		return err
+	}
+	viewer, err := command(cfg.Viewer, config.TemplateData{Input: plainPath, Dir: filepath.Dir(plainPath)})
+	if err != nil {
+		return fmt.Errorf("viewer: %w", err)
+	}
+	if err := viewer.Start(); err != nil {
+		return fmt.Errorf("opening viewer: %w", err)
+	}
+	// Reap it, so long-running callers like tarragon serve leave no zombies.
+	go viewer.Wait()
+	return nil
+}
+
+// OpenFolderOf opens the directory containing path with the file manager
+// command.
+func OpenFolderOf(cfg config.Config, path string) error {
+	if err := checkExists(path); err != nil {
+		return err
+	}
+	fm, err := command(cfg.FileManager, config.TemplateData{Input: path, Dir: filepath.Dir(path)})
+	if err != nil {
+		return fmt.Errorf("file manager: %w", err)
+	}
+	if err := fm.Start(); err != nil {
+		return fmt.Errorf("opening folder: %w", err)
+	}
+	go fm.Wait()
+	return nil
+}
+
+// CopyImage puts the (possibly encrypted) image at path on the clipboard
+// with the image_clipboard command.
+func CopyImage(cfg config.Config, path, mimeType string) error {
+	file, err := vault.OpenImage(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file no longer exists: %s", path)
+		}
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	copyCmd, err := command(cfg.ImageClipboard, config.TemplateData{MimeType: mimeType})
+	if err != nil {
+		return fmt.Errorf("image clipboard: %w", err)
+	}
+	copyCmd.Stdin = file
+	if err := copyCmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", cfg.ImageClipboard.Cmd, err)
+	}
+	return nil
+}
+
+// CopyText puts text on the clipboard with the capture.clipboard command.
+func CopyText(cfg config.Config, text string) error {
+	copyCmd, err := command(cfg.Capture.Clipboard, config.TemplateData{})
+	if err != nil {
+		return fmt.Errorf("clipboard: %w", err)
+	}
+	copyCmd.Stdin = strings.NewReader(text)
+	if err := copyCmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", cfg.Capture.Clipboard.Cmd, err)
+	}
+	return nil
+}
+
+// ShowInTerminal renders the (possibly encrypted) image at path in the
+// terminal with the terminal_image command. The image is streamed to its
+// stdin; a decrypted copy is only written when the args refer to
+// {{.Input}} or {{.Dir}}.
+func ShowInTerminal(cfg config.Config, path string) error {
+	data := config.TemplateData{MimeType: imaging.MimeType(vault.PlainName(path)), Time: time.Now()}
+	needsPath, err := refersToPath(cfg.TerminalImage.Args, data)
+	if err != nil {
+		return fmt.Errorf("terminal image: %w", err)
+	}
+	if needsPath {
+		plainPath, err := vault.PlainPath(path)
+		if err != nil {
+			return err
+		}
+		data.Input, data.Dir = plainPath, filepath.Dir(plainPath)
+	}
+
+	file, err := vault.OpenImage(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	show, err := command(cfg.TerminalImage, data)
+	if err != nil {
+		return fmt.Errorf("terminal image: %w", err)
+	}
+	show.Stdin = file
+	show.Stdout = os.Stdout
+	show.Stderr = os.Stderr
+	if err := show.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", cfg.TerminalImage.Cmd, err)
+	}
+	return nil
+}
+
+// refersToPath reports whether args use {{.Input}} or {{.Dir}}, by
+// rendering them with two different paths and comparing.
+func refersToPath(args []string, data config.TemplateData) (bool, error) {
+	a, b := data, data
+	a.Input, a.Dir = "/a/input", "/a"
+	b.Input, b.Dir = "/b/input", "/b"
+	ra, err := config.RenderArgs(args, a)
+	if err != nil {
+		return false, err
+	}
+	rb, err := config.RenderArgs(args, b)
+	if err != nil {
+		return false, err
+	}
+	return !slices.Equal(ra, rb), nil
+}
+
+// OCR runs the configured OCR command on the image of sc and returns the
+// recognized text.
+func OCR(cmd config.CommandConfig, sc *models.Screenshot) (string, error) {
+	plainPath, err := vault.PlainPath(sc.FilePath)
+	if err != nil {
+		return "", err
+	}
+	data := config.NewTemplateData(sc)
+	data.Input = plainPath
+	args, err := config.RenderArgs(cmd.Args, data)
+	if err != nil {
+		return "", err
+	}
+	var stderr bytes.Buffer
+	ocrCmd := exec.Command(cmd.Cmd, args...)
+	ocrCmd.Stderr = &stderr
+	out, err := ocrCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("ocr command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return "", fmt.Errorf("no text recognized")
+	}
+	return text, nil
+}
+
+func checkExists(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file no longer exists: %s", path)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package apprules picks the app rule for the focused window and applies
+// its capture overrides to the configuration.
+package apprules
+
+import (
+	"fmt"
+	"regexp"
+
+	"orego/internal/config"
+	"orego/internal/imaging"
+	"orego/pkg/models"
+)
+
+// Rule is the compiled form of config.AppRule. Nil patterns match
+// anything, but at least one pattern is required.
+type Rule struct {
+	config.AppRule
+	class *regexp.Regexp
+	title *regexp.Regexp
+}
+
+func Compile(rules []config.AppRule) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("app rule %d", i+1)
+		}
+		if r.Class == "" && r.Title == "" {
+			return nil, fmt.Errorf("%s: needs a class or title pattern", r.Name)
+		}
+
+		if r.Format != "" {
+			if _, err := imaging.NormalizeFormat(r.Format); err != nil {
+				return nil, fmt.Errorf("%s: %w", r.Name, err)
+			}
+		}
+
+		rule := Rule{AppRule: r}
+		var err error
+		if r.Class != "" {
+			if rule.class, err = regexp.Compile(r.Class); err != nil {
+				return nil, fmt.Errorf("%s: invalid class pattern: %w", r.Name, err)
+			}
+		}
+		if r.Title != "" {
+			if rule.title, err = regexp.Compile(r.Title); err != nil {
+				return nil, fmt.Errorf("%s: invalid title pattern: %w", r.Name, err)
+			}
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+// Match returns the first rule matching the active window of sc, or nil
+// if none does.
+func Match(rules []Rule, sc *models.Screenshot) *Rule {
+	w := sc.ActiveWindow
+	if w.Class == "" && w.Title == "" {
+		return nil
+	}
+	for i, r := range rules {
+		if r.class != nil && !r.class.MatchString(w.Class) {
+			continue
+		}
+		if r.title != nil && !r.title.MatchString(w.Title) {
+			continue
+		}
+		return &rules[i]
+	}
+	return nil
+}
+
+// named returns the rule called name, or nil if there is none.
+func named(rules []Rule, name string) *Rule {
+	for i, r := range rules {
+		if r.Name == name {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// ForScreenshot returns cfg with the overrides of the app rule sc was
+// captured with, for actions run on it later. cfg is returned unchanged
+// if sc has no app rule or it is no longer configured.
+func ForScreenshot(cfg config.Config, sc *models.Screenshot) config.Config {
+	if sc.AppRule == "" {
+		return cfg
+	}
+	compiled, err := Compile(cfg.AppRules)
+	if err != nil {
+		return cfg
+	}
+	if r := named(compiled, sc.AppRule); r != nil {
+		return r.Apply(cfg)
+	}
+	return cfg
+}
+
+// Apply returns cfg with the rule's overrides.
+func (r *Rule) Apply(cfg config.Config) config.Config {
+	editor := &cfg.Capture.Editor
+	if r.Editor.Cmd != "" {
+		editor.Cmd = r.Editor.Cmd
+	}
+	if r.Editor.Args != nil {
+		editor.Args = r.Editor.Args
+	}
+	if r.Editor.ArgsOCR != nil {
+		editor.ArgsOCR = r.Editor.ArgsOCR
+	}
+	if r.Format != "" {
+		cfg.Storage.Format = r.Format
+	}
+	if r.Quality != 0 {
+		cfg.Storage.Quality = r.Quality
+	}
+	if r.Dir != "" {
+		cfg.Storage.Dir = r.Dir
+	}
+	if r.OCRArgs != nil {
+		cfg.Capture.OCR.Args = r.OCRArgs
+	}
+	return cfg
+}
+
+// Notification renders the rule's notification text for sc, starting
+// from the default title and body.
+func (r *Rule) Notification(sc *models.Screenshot, title, body string) (string, string, error) {
+	data := config.NewTemplateData(sc)
+	data.Title, data.Body = title, body
+	rendered, err := config.RenderArgs([]string{r.NotifyTitle, r.NotifyBody}, data)
+	if err != nil {
+		return title, body, fmt.Errorf("app rule %s: %w", r.Name, err)
+	}
+	if r.NotifyTitle != "" {
+		title = rendered[0]
+	}
+	if r.NotifyBody != "" {
+		body = rendered[1]
+	}
+	return title, body, nil
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/db"
+)
+
+var (
+	albumDescription string
+	albumQuery       string
+	albumUseClear    bool
+)
+
+var albumCmd = &cobra.Command{
+	Use:   "album",
+	Short: "Group related screenshots into albums",
+}
+
+var albumCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an empty album",
+	Args:  cobra.ExactArgs(1),
+	Run:   runAlbumCreate,
+}
+
+var albumAddCmd = &cobra.Command{
+	Use:   "add [name] [id|range|list]...",
+	Short: "Add screenshots to an album",
+	Args:  albumSelectorArgs,
+	Run:   runAlbumAdd,
+}
+
+var albumRmCmd = &cobra.Command{
+	Use:   "rm [name] [id|range|list]...",
+	Short: "Remove screenshots from an album (the screenshots are kept)",
+	Args:  albumSelectorArgs,
+	Run:   runAlbumRm,
+}
+
+var albumLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List albums",
+	Args:  cobra.NoArgs,
+	Run:   runAlbumLs,
+}
+
+var albumShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "List the screenshots in an album",
+	Args:  cobra.ExactArgs(1),
+	Run:   runAlbumShow,
+}
+
+var albumDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete an album (the screenshots are kept)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runAlbumDelete,
+}
+
+var albumUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Add every new capture to this album",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runAlbumUse,
+}
+
+func init() {
+	albumCreateCmd.Flags().StringVarP(&albumDescription, "description", "d", "", "What the album is about")
+	addSelectorFlags(albumAddCmd, &albumQuery)
+	addSelectorFlags(albumRmCmd, &albumQuery)
+	albumUseCmd.Flags().BoolVar(&albumUseClear, "clear", false, "Stop adding captures to an album")
+	albumCmd.AddCommand(albumCreateCmd)
+	albumCmd.AddCommand(albumAddCmd)
+	albumCmd.AddCommand(albumRmCmd)
+	albumCmd.AddCommand(albumLsCmd)
+	albumCmd.AddCommand(albumShowCmd)
+	albumCmd.AddCommand(albumDeleteCmd)
+	albumCmd.AddCommand(albumUseCmd)
+	rootCmd.AddCommand(albumCmd)
+}
+
+// albumSelectorArgs requires an album name followed by IDs or --query.
+func albumSelectorArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("requires an album name")
+	}
+	return selectorArgs(cmd, args[1:])
+}
+
+func runAlbumCreate(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	name := strings.TrimSpace(args[0])
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "Album name must not be empty.")
+		os.Exit(1)
+	}
+	if err := store.CreateAlbum(name, albumDescription); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created album %q\n", name)
+}
+
+func runAlbumAdd(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	ids, err := resolveSelection(store, args[1:], albumQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	added, err := store.AddToAlbum(args[0], ids)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added %d screenshot(s) to %q\n", added, args[0])
+}
+
+func runAlbumRm(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	ids, err := resolveSelection(store, args[1:], albumQuery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	removed, err := store.RemoveFromAlbum(args[0], ids)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %d screenshot(s) from %q\n", removed, args[0])
+}
+
+func runAlbumLs(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	albums, err := store.ListAlbums()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing albums: %v\n", err)
+		os.Exit(1)
+	}
+	if len(albums) == 0 {
+		fmt.Println("No albums yet. Create one with 'orego album create <name>'.")
+		return
+	}
+	current, err := store.CurrentAlbum()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCOUNT\tCREATED\tDESCRIPTION")
+	for _, a := range albums {
+		name := a.Name
+		if name == current {
+			name += " *"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", name, a.Count, a.CreatedAt.Local().Format("2006-01-02 15:04"), a.Description)
+	}
+	w.Flush()
+}
+
+func runAlbumShow(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	album, err := store.GetAlbum(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	screenshots, err := store.FindScreenshots(db.ListOptions{Album: album.Name})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s (%d screenshots)\n", album.Name, album.Count)
+	if album.Description != "" {
+		fmt.Println(album.Description)
+	}
+	fmt.Println()
+	printScreenshotTable(screenshots)
+}
+
+func runAlbumDelete(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if err := store.DeleteAlbum(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted album %q\n", args[0])
+}
+
+func runAlbumUse(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if albumUseClear {
+		if err := store.SetCurrentAlbum(""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("New captures will not be added to an album.")
+		return
+	}
+
+	if len(args) == 0 {
+		current, err := store.CurrentAlbum()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if current == "" {
+			fmt.Println("No current album.")
+		} else {
+			fmt.Println(current)
+		}
+		return
+	}
+
+	if err := store.SetCurrentAlbum(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("New captures will be added to %q.\n", args[0])
+}
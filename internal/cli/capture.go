@@ -2,28 +2,56 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"orego/internal/actions"
+	"orego/internal/apprules"
 	"orego/internal/config"
 	"orego/internal/db"
+	"orego/internal/hooks"
+	"orego/internal/imaging"
+	"orego/internal/pipeline"
+	"orego/internal/privacy"
+	"orego/internal/redact"
+	"orego/internal/retention"
+	"orego/internal/vault"
 	"orego/pkg/hyprland"
+	"orego/pkg/models"
 )
 
 var (
 	timeout      time.Duration
 	ocr          bool
 	all          bool
+	redactFlag   bool
+	noEdit       bool
 	grimCmd      string
 	editorCmd    string
 	ocrCmd       string
 	clipboardCmd string
 	notifyCmd    string
+	delay        time.Duration
+	settle       time.Duration
+	interval     time.Duration
+	count        int
+	until        string
+	sessionName  string
+	onFocus      string
+	captureAlbum string
+	toClipboard  bool
+	copyOnly     bool
+	noNotify     bool
 )
 
 var captureCmd = &cobra.Command{
@@ -33,9 +61,23 @@ var captureCmd = &cobra.Command{
 }
 
 func init() {
-	captureCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Max time to wait for file creation after editor closes")
+	captureCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Max time to wait for the editor's output after it closes")
 	captureCmd.Flags().BoolVar(&ocr, "ocr", false, "Perform OCR and copy to clipboard (no DB save)")
 	captureCmd.Flags().BoolVar(&all, "all", false, "Capture all visible workspaces")
+	captureCmd.Flags().BoolVar(&noEdit, "no-edit", false, "Save immediately without running the editor pipeline")
+	captureCmd.Flags().BoolVar(&redactFlag, "redact", false, "Redact sensitive text before editing (overrides capture.redaction.enabled)")
+	captureCmd.Flags().DurationVar(&delay, "delay", 0, "Wait this long before capturing, with a countdown notification")
+	captureCmd.Flags().DurationVar(&settle, "settle", 0, "Wait this long before capturing, without a countdown (lets a launcher close first)")
+	_ = captureCmd.Flags().MarkHidden("settle")
+	captureCmd.Flags().DurationVar(&interval, "interval", 0, "Capture repeatedly at this interval (time-lapse, skips the editor)")
+	captureCmd.Flags().IntVar(&count, "count", 0, "Number of frames to capture with --interval (0 = until stopped)")
+	captureCmd.Flags().StringVar(&until, "until", "", "Stop waiting or capturing at this time (e.g. 17:30, 2h)")
+	captureCmd.Flags().StringVar(&sessionName, "session", "", "Group captures under this session name")
+	captureCmd.Flags().StringVar(&captureAlbum, "album", "", "Add the capture to this album (default: the current album)")
+	captureCmd.Flags().BoolVar(&toClipboard, "clipboard", false, "Also copy the saved screenshot to the clipboard")
+	captureCmd.Flags().BoolVar(&copyOnly, "copy-only", false, "Copy to the clipboard and keep only a temporary entry (see retention.ephemeral_ttl)")
+	captureCmd.Flags().BoolVar(&noNotify, "no-notify", false, "Skip the notification with actions after saving")
+	captureCmd.Flags().StringVar(&onFocus, "on-focus", "", "Wait until a window whose class matches this regex gains focus")
 	captureCmd.Flags().StringVar(&grimCmd, "grim-cmd", "grim", "Command used to capture screenshots")
 	captureCmd.Flags().StringVar(&editorCmd, "editor-cmd", "satty", "Command used to edit/annotate screenshots")
 	captureCmd.Flags().StringVar(&ocrCmd, "ocr-cmd", "tesseract", "Command used to perform OCR")
@@ -44,17 +86,12 @@ func init() {
 	rootCmd.AddCommand(captureCmd)
 }
 
-func runOCRFlow(cmd *cobra.Command, tmpPath string) error {
+func runOCRFlow(cmd *cobra.Command, cfg config.Config, sc *models.Screenshot, tmpPath string) error {
 	ocrPath := filepath.Join(
 		os.TempDir(),
 		fmt.Sprintf("orego-ocr-%d.png", time.Now().UnixNano()),
 	)
 
-	cfg, err := config.Load()
-	if err != nil {
-		return err
-	}
-
 	fmt.Println("Opening editor for OCR... (Crop if needed, then click Save)")
 
 	editorCmdToUse := editorCmd
@@ -62,10 +99,9 @@ func runOCRFlow(cmd *cobra.Command, tmpPath string) error {
 		editorCmdToUse = cfg.Capture.Editor.Cmd
 	}
 
-	ocrEditorArgs, err := config.RenderArgs(cfg.Capture.Editor.ArgsOCR, map[string]string{
-		"Input":  tmpPath,
-		"Output": ocrPath,
-	})
+	editorData := config.NewTemplateData(sc)
+	editorData.Input, editorData.Output = tmpPath, ocrPath
+	ocrEditorArgs, err := config.RenderArgs(cfg.Capture.Editor.ArgsOCR, editorData)
 	if err != nil {
 		return err
 	}
@@ -84,9 +120,9 @@ func runOCRFlow(cmd *cobra.Command, tmpPath string) error {
 		ocrCmdToUse = cfg.Capture.OCR.Cmd
 	}
 
-	ocrArgs, err := config.RenderArgs(cfg.Capture.OCR.Args, map[string]string{
-		"Input": ocrPath,
-	})
+	ocrData := config.NewTemplateData(sc)
+	ocrData.Input = ocrPath
+	ocrArgs, err := config.RenderArgs(cfg.Capture.OCR.Args, ocrData)
 	if err != nil {
 		return err
 	}
@@ -102,7 +138,7 @@ func runOCRFlow(cmd *cobra.Command, tmpPath string) error {
 		clipboardCmdToUse = cfg.Capture.Clipboard.Cmd
 	}
 
-	clipboardArgs, err := config.RenderArgs(cfg.Capture.Clipboard.Args, map[string]string{})
+	clipboardArgs, err := config.RenderArgs(cfg.Capture.Clipboard.Args, config.NewTemplateData(sc))
 	if err != nil {
 		return err
 	}
@@ -113,154 +149,534 @@ func runOCRFlow(cmd *cobra.Command, tmpPath string) error {
 		return fmt.Errorf("clipboard command failed: %w", err)
 	}
 
+	sendNotification(cmd, cfg, "OCR", "Text copied to clipboard")
+	return nil
+}
+
+// runRedaction OCRs the image at path, pixelates every region matched by a
+// redaction rule in place and returns how often each rule fired.
+func runRedaction(cfg config.RedactionConfig, sc *models.Screenshot, path string) ([]models.Redaction, error) {
+	rules, err := redact.CompileRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	ocrData := config.NewTemplateData(sc)
+	ocrData.Input = path
+	ocrArgs, err := config.RenderArgs(cfg.OCR.Args, ocrData)
+	if err != nil {
+		return nil, err
+	}
+
+	tsv, err := exec.Command(cfg.OCR.Cmd, ocrArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ocr command failed: %w", err)
+	}
+
+	words, err := redact.ParseTSV(tsv)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := redact.Find(words, rules)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	img, err := imaging.LoadPNG(path)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, m := range matches {
+		imaging.Pixelate(img, m.Box.Inset(-cfg.Padding), cfg.BlockSize)
+		counts[m.Rule]++
+	}
+
+	if err := imaging.SavePNG(path, img); err != nil {
+		return nil, err
+	}
+
+	redactions := make([]models.Redaction, 0, len(counts))
+	for _, rule := range rules {
+		if n, ok := counts[rule.Name]; ok {
+			redactions = append(redactions, models.Redaction{Rule: rule.Name, Count: n})
+		}
+	}
+	return redactions, nil
+}
+
+// blurWindows blurs the given layout geometries in the image at path.
+func blurWindows(path string, windows []models.Geometry, region models.Geometry, scale float64, radius int) error {
+	img, err := imaging.LoadPNG(path)
+	if err != nil {
+		return err
+	}
+	for _, g := range windows {
+		imaging.Blur(img, privacy.ImageRect(g, region, scale), radius)
+	}
+	return imaging.SavePNG(path, img)
+}
+
+// sendNotification shows a desktop notification using the configured
+// notify command. Failures are ignored, notifications are best effort.
+func sendNotification(cmd *cobra.Command, cfg config.Config, title, body string) {
 	notifyCmdToUse := notifyCmd
 	if !cmd.Flags().Changed("notify-cmd") && cfg.Capture.Notify.Cmd != "" {
 		notifyCmdToUse = cfg.Capture.Notify.Cmd
 	}
 
-	notifyArgs, err := config.RenderArgs(cfg.Capture.Notify.Args, map[string]string{
-		"Title": "OCR",
-		"Body":  "Text copied to clipboard",
+	notifyArgs, err := config.RenderArgs(cfg.Capture.Notify.Args, config.TemplateData{
+		Title: title,
+		Body:  body,
 	})
 	if err != nil {
-		return err
+		return
 	}
 
 	exec.Command(notifyCmdToUse, notifyArgs...).Run()
-	return nil
 }
 
 func runCapture(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	data, err := hyprland.GetScreenshotData(all)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching data: %v\n", err)
+	if err := validateCaptureModes(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-
-	cfg, err := config.Load()
+	if _, err := imaging.NormalizeFormat(cfg.Storage.Format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in storage config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := hooks.Validate(cfg.Hooks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in hooks config: %v\n", err)
+		os.Exit(1)
+	}
+	appRules, err := apprules.Compile(cfg.AppRules)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error in app_rules config: %v\n", err)
 		os.Exit(1)
 	}
-
-	tmpFile, err := os.CreateTemp("", "orego-raw-*.png")
+	deadline, err := parseUntil(until, time.Now())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating temp file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	tmpPath := tmpFile.Name()
-	tmpFile.Close()
-	defer os.Remove(tmpPath)
 
-	monitor := data.Workspace.Monitor
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	grimCmdToUse := grimCmd
-	if !cmd.Flags().Changed("grim-cmd") && cfg.Capture.Grim.Cmd != "" {
-		grimCmdToUse = cfg.Capture.Grim.Cmd
+	if onFocus != "" {
+		pattern, err := regexp.Compile(onFocus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --on-focus pattern: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Waiting for a window matching %q to gain focus...\n", onFocus)
+		if err := waitForFocus(ctx, pattern, deadline); err != nil {
+			fmt.Fprintf(os.Stderr, "Capture cancelled: %v\n", err)
+			return
+		}
 	}
 
-	grimArgsTemplate := cfg.Capture.Grim.ArgsAll
-	if !all && monitor != "" && monitor != "all-visible" {
-		grimArgsTemplate = cfg.Capture.Grim.ArgsSingle
+	if delay > 0 {
+		if err := countdown(ctx, cfg, delay); err != nil {
+			fmt.Fprintln(os.Stderr, "Capture cancelled.")
+			return
+		}
+	}
+	if settle > 0 {
+		select {
+		case <-time.After(settle):
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, "Capture cancelled.")
+			return
+		}
 	}
 
-	grimArgs, err := config.RenderArgs(grimArgsTemplate, map[string]string{
-		"Monitor": monitor,
-		"Output":  tmpPath,
-	})
+	if interval > 0 {
+		runTimelapse(ctx, cmd, cfg, appRules, deadline)
+		return
+	}
+
+	if !noEdit && !ocr {
+		if err := pipeline.Validate(cfg.Capture.Pipeline); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in capture pipeline: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	data, rule, err := captureContext(appRules)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error rendering grim args: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if rule != nil {
+		cfg = rule.Apply(cfg)
+	}
 
-	if err := exec.Command(grimCmdToUse, grimArgs...).Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error running grim: %v\n", err)
+	tmpPath, err := grabScreenshot(cmd, cfg, data, !ocr)
+	if err != nil {
+		var blocked errCaptureBlocked
+		if errors.As(err, &blocked) {
+			fmt.Fprintf(os.Stderr, "Capture blocked by privacy rule %q.\n", string(blocked))
+			sendNotification(cmd, cfg, "OreGo", fmt.Sprintf("Capture blocked by privacy rule %q", string(blocked)))
+			return
+		}
+		var vetoed errCaptureVetoed
+		if errors.As(err, &vetoed) {
+			fmt.Fprintf(os.Stderr, "Capture cancelled: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	defer os.Remove(tmpPath)
 
 	if ocr {
-		if err := runOCRFlow(cmd, tmpPath); err != nil {
+		if err := runOCRFlow(cmd, cfg, data, tmpPath); err != nil {
 			fmt.Fprintf(os.Stderr, "OCR failed: %v\n", err)
 			os.Exit(1)
 		}
 		return // Exit without saving to DB
 	}
 
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	screenshotsDir := filepath.Join(homeDir, "Pictures", "Screenshots")
-	if err := os.MkdirAll(screenshotsDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating screenshots dir: %v\n", err)
+	if sessionName != "" {
+		if data.SessionID, err = store.StartSession(sessionName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		data.Session = sessionName
+	}
+	if data.Albums, err = captureAlbums(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	targetFilename := fmt.Sprintf("%s_orego.png", timestamp)
-	targetPath := filepath.Join(screenshotsDir, targetFilename)
+	storage := cfg.Storage
+	if copyOnly {
+		if storage.Dir, err = ephemeralDir(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ttl, err := retention.ParseAge(cfg.Retention.EphemeralTTL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid retention.ephemeral_ttl: %v\n", err)
+			os.Exit(1)
+		}
+		expires := data.Capture.Ts.Add(ttl)
+		data.ExpiresAt = &expires
+		purgeExpired(store)
+	}
 
-	fmt.Println("Opening editor... (Waiting for you to save and close the window)")
-	editorCmdToUse := editorCmd
-	if !cmd.Flags().Changed("editor-cmd") && cfg.Capture.Editor.Cmd != "" {
-		editorCmdToUse = cfg.Capture.Editor.Cmd
+	editorConfig := captureEditor(cmd, cfg)
+	stages := cfg.Capture.Pipeline
+	if noEdit {
+		stages = []config.StageConfig{{Type: pipeline.StageNone}}
+	}
+	if pipelineUsesEditor(stages) {
+		fmt.Println("Opening editor... (Waiting for you to save and close the window)")
 	}
 
-	editorArgs, err := config.RenderArgs(cfg.Capture.Editor.Args, map[string]string{
-		"Input":  tmpPath,
-		"Output": targetPath,
-	})
+	outcome, err := saveScreenshot(store, data, tmpPath, stages, editorConfig, storage)
+	if errors.Is(err, pipeline.ErrDiscarded) {
+		fmt.Fprintln(os.Stderr, "Screenshot discarded.")
+		return
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error rendering editor args: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	hookErr := hooks.Run(hooks.PostSave, cfg.Hooks.PostSave, data)
+
+	notifyTitle := "Screenshot saved"
+	if toClipboard || copyOnly {
+		if err := actions.CopyImage(cfg, data.FilePath, data.MimeType); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
+			os.Exit(1)
+		}
+		notifyTitle = "Screenshot copied to clipboard"
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(data)
+
+	if outcome.EditLater && vault.IsEncrypted(data.FilePath) {
+		fmt.Fprintln(os.Stderr, "Skipping edit-later: the saved screenshot is encrypted. Use 'orego view' to open it.")
+	} else if outcome.EditLater {
+		editCmd, err := pipeline.EditLaterCommand(editorConfig, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering editor args: %v\n", err)
+			os.Exit(1)
+		}
+		if err := editCmd.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting editor: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if !noNotify {
+		notifyBody := captureSummary(data)
+		if rule != nil {
+			if notifyTitle, notifyBody, err = rule.Notification(data, notifyTitle, notifyBody); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering notification: %v\n", err)
+			}
+		}
+		notifyInBackground(cfg, data, notifyTitle, notifyBody)
+	}
+
+	if hookErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", hookErr)
 		os.Exit(1)
 	}
+}
+
+// errCaptureBlocked names the privacy rule that prevented a capture.
+type errCaptureBlocked string
+
+func (e errCaptureBlocked) Error() string {
+	return fmt.Sprintf("capture blocked by privacy rule %q", string(e))
+}
 
-	sattyCmd := exec.Command(editorCmdToUse, editorArgs...)
-	sattyCmd.Stdin = os.Stdin
-	sattyCmd.Stdout = os.Stdout
-	sattyCmd.Stderr = os.Stderr
+// errCaptureVetoed wraps the failure of a pre_capture hook with
+// on_error "abort".
+type errCaptureVetoed struct{ err error }
 
-	if err := sattyCmd.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Editor exited with: %v\n", err)
+func (e errCaptureVetoed) Error() string { return e.err.Error() }
+
+func (e errCaptureVetoed) Unwrap() error { return e.err }
+
+// captureContext fetches the window context for a capture and picks the
+// app rule matching it. Callers apply the rule before grabScreenshot, so
+// privacy rules, hooks and the editor all see it.
+func captureContext(appRules []apprules.Rule) (*models.Screenshot, *apprules.Rule, error) {
+	data, err := hyprland.GetScreenshotData(all)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch data: %w", err)
+	}
+	rule := apprules.Match(appRules, data)
+	if rule != nil {
+		data.AppRule = rule.Name
 	}
+	return data, rule, nil
+}
 
-	start := time.Now()
-	found := false
-	for {
-		if _, err := os.Stat(targetPath); err == nil {
-			found = true
-			break
+// grabScreenshot applies privacy rules and the pre_capture hook to data,
+// grabs the screen to a temporary PNG and returns its path.
+func grabScreenshot(cmd *cobra.Command, cfg config.Config, data *models.Screenshot, redactText bool) (string, error) {
+	privacyRules, err := privacy.CompileRules(cfg.Privacy.Rules)
+	if err != nil {
+		return "", fmt.Errorf("failed to load privacy rules: %w", err)
+	}
+	decision := privacy.Evaluate(privacyRules, data)
+	if decision.BlockedBy != "" {
+		return "", errCaptureBlocked(decision.BlockedBy)
+	}
+	// Stripped before the hook, which must not see what the rule hides.
+	if decision.NoMetadata {
+		privacy.StripMetadata(data)
+	}
+	if err := hooks.Run(hooks.PreCapture, cfg.Hooks.PreCapture, data); err != nil {
+		return "", errCaptureVetoed{err}
+	}
+
+	tmpFile, err := os.CreateTemp("", "orego-raw-*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+
+	fail := func(format string, err error) (string, error) {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf(format, err)
+	}
+
+	monitor := data.Workspace.Monitor
+
+	grimCmdToUse := grimCmd
+	if !cmd.Flags().Changed("grim-cmd") && cfg.Capture.Grim.Cmd != "" {
+		grimCmdToUse = cfg.Capture.Grim.Cmd
+	}
+
+	grimArgsTemplate := cfg.Capture.Grim.ArgsAll
+	if !all && monitor != "" && monitor != "all-visible" {
+		grimArgsTemplate = cfg.Capture.Grim.ArgsSingle
+	}
+
+	grimData := config.NewTemplateData(data)
+	grimData.Monitor, grimData.Output = monitor, tmpPath
+	grimArgs, err := config.RenderArgs(grimArgsTemplate, grimData)
+	if err != nil {
+		return fail("failed to render grim args: %w", err)
+	}
+
+	if err := exec.Command(grimCmdToUse, grimArgs...).Run(); err != nil {
+		return fail("failed to run grim: %w", err)
+	}
+
+	if len(decision.Blur) > 0 && data.Region != nil {
+		if err := blurWindows(tmpPath, decision.Blur, *data.Region, data.Scale, cfg.Privacy.BlurRadius); err != nil {
+			return fail("failed to blur windows: %w", err)
+		}
+	}
+
+	if !redactText {
+		return tmpPath, nil
+	}
+
+	if cmd.Flags().Changed("redact") {
+		cfg.Capture.Redaction.Enabled = redactFlag
+	}
+	if cfg.Capture.Redaction.Enabled {
+		redactions, err := runRedaction(cfg.Capture.Redaction, data, tmpPath)
+		if err != nil {
+			return fail("failed to redact screenshot: %w", err)
 		}
-		if time.Since(start) > timeout {
-			break
+		for _, r := range redactions {
+			fmt.Printf("Redacted %d region(s) matching %q\n", r.Count, r.Rule)
 		}
-		time.Sleep(500 * time.Millisecond)
-		fmt.Print(".") // Feedback dot
+		data.Redactions = redactions
 	}
-	if !found {
-		fmt.Println()
-		fmt.Fprintln(os.Stderr, "Screenshot discarded (not saved within timeout).")
-		return
+
+	return tmpPath, nil
+}
+
+// saveScreenshot runs the raw capture through the pipeline, stores the
+// result in the configured format, encrypts it if the vault is set up
+// and records it.
+func saveScreenshot(store *db.Store, data *models.Screenshot, tmpPath string, stages []config.StageConfig, editor config.EditorConfig, storage config.StorageConfig) (pipeline.Outcome, error) {
+	format, err := imaging.NormalizeFormat(storage.Format)
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+
+	screenshotsDir, err := mediaDir(storage.Dir, "Pictures", "Screenshots")
+	if err != nil {
+		return pipeline.Outcome{}, err
+	}
+
+	// Editors read and write PNG; conversion happens once at the end.
+	pipelineOut := filepath.Join(os.TempDir(), fmt.Sprintf("orego-out-%d.png", time.Now().UnixNano()))
+	defer os.Remove(pipelineOut)
+
+	outcome, err := pipeline.Run(stages, tmpPath, pipelineOut, pipeline.Options{
+		Editor:     editor,
+		Screenshot: data,
+		Timeout:    timeout,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+	})
+	if errors.Is(err, pipeline.ErrDiscarded) {
+		return outcome, err
+	}
+	if err != nil {
+		return outcome, fmt.Errorf("capture pipeline failed: %w", err)
+	}
+
+	if data.Width, data.Height, err = imaging.Dimensions(pipelineOut); err != nil {
+		return outcome, err
+	}
+	name := data.Capture.Ts.Format("2006-01-02_15-04-05") + "_orego"
+	targetPath, err := reserveFile(screenshotsDir, name, imaging.Extension(format))
+	if err != nil {
+		return outcome, err
+	}
+	if err := encodeForStorage(storage, format, pipelineOut, targetPath, data); err != nil {
+		os.Remove(targetPath)
+		return outcome, err
+	}
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		return outcome, err
 	}
-	fmt.Println()
+	data.Size = info.Size()
+	data.MimeType = imaging.MimeType(targetPath)
 
 	data.FilePath = targetPath
+	if v, err := vault.Current(); err != nil {
+		return outcome, fmt.Errorf("failed to load vault: %w", err)
+	} else if v != nil {
+		encPath, err := v.EncryptFile(targetPath)
+		if err != nil {
+			return outcome, fmt.Errorf("failed to encrypt screenshot: %w", err)
+		}
+		data.FilePath = encPath
+	}
+
 	if err := store.Save(data); err != nil {
-		fmt.Fprintf(os.Stderr, "Error saving to DB: %v\n", err)
-		os.Exit(1)
+		return outcome, fmt.Errorf("failed to save to DB: %w", err)
 	}
+	return outcome, nil
+}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	encoder.Encode(data)
+// captureAlbums returns the albums a new capture joins: --album if given,
+// otherwise the current album set with 'orego album use'.
+func captureAlbums(store *db.Store) ([]string, error) {
+	album := captureAlbum
+	if album == "" {
+		current, err := store.CurrentAlbum()
+		if err != nil {
+			return nil, err
+		}
+		album = current
+	}
+	if album == "" {
+		return nil, nil
+	}
+	return []string{album}, nil
+}
+
+// ephemeralDir is where --copy-only captures are kept until they expire.
+func ephemeralDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache dir: %w", err)
+	}
+	return mediaDir(filepath.Join(cacheDir, "orego", "ephemeral"))
+}
+
+// purgeExpired deletes ephemeral entries past their expiry. It runs
+// opportunistically, so failures are only reported.
+func purgeExpired(store *db.Store) {
+	ids, err := store.ExpiredIDs(time.Now())
+	if err == nil && len(ids) > 0 {
+		err = store.DeleteScreenshots(ids)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove expired entries: %v\n", err)
+	}
+}
+
+// captureEditor resolves the editor from config and --editor-cmd.
+func captureEditor(cmd *cobra.Command, cfg config.Config) config.EditorConfig {
+	editorConfig := cfg.Capture.Editor
+	if cmd.Flags().Changed("editor-cmd") || editorConfig.Cmd == "" {
+		editorConfig.Cmd = editorCmd
+	}
+	return editorConfig
+}
+
+func pipelineUsesEditor(stages []config.StageConfig) bool {
+	for _, st := range stages {
+		if st.Type == pipeline.StageEditor || st.Type == pipeline.StageSwappy {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/apprules"
+	"orego/internal/config"
+	"orego/internal/hooks"
+	"orego/internal/pipeline"
+	"orego/pkg/hyprland"
+)
+
+const focusPollInterval = 250 * time.Millisecond
+
+var errUntilReached = errors.New("--until time reached")
+
+// validateCaptureModes rejects flag combinations that make no sense
+// together, e.g. an OCR time-lapse.
+func validateCaptureModes() error {
+	if delay < 0 || interval < 0 || count < 0 {
+		return fmt.Errorf("--delay, --interval and --count must not be negative")
+	}
+	if interval > 0 && interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if interval == 0 && count > 0 {
+		return fmt.Errorf("--count needs --interval")
+	}
+	if until != "" && interval == 0 && onFocus == "" {
+		return fmt.Errorf("--until needs --interval or --on-focus")
+	}
+	if interval > 0 && ocr {
+		return fmt.Errorf("--ocr cannot be combined with --interval")
+	}
+	if (toClipboard || copyOnly) && (interval > 0 || ocr) {
+		return fmt.Errorf("--clipboard and --copy-only cannot be combined with --interval or --ocr")
+	}
+	return nil
+}
+
+// parseUntil accepts a duration from now ("90m"), a clock time ("17:30",
+// today or tomorrow, whichever comes first) or a full local date and time.
+// An empty value means no deadline.
+func parseUntil(v string, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(d), nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+			if !at.After(now) {
+				at = at.AddDate(0, 0, 1)
+			}
+			return at, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --until %q: expected a duration, HH:MM or YYYY-MM-DD HH:MM", v)
+}
+
+// sleepUntil waits until t, returning early if ctx is cancelled.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// countdown waits for d, updating a notification once per second so the
+// user knows when the capture will happen.
+func countdown(ctx context.Context, cfg config.Config, d time.Duration) error {
+	fmt.Fprintf(os.Stderr, "Capturing in %s...\n", d)
+	end := time.Now().Add(d)
+	for {
+		remaining := time.Until(end)
+		if remaining <= 0 {
+			return nil
+		}
+		secs := int((remaining + time.Second - 1) / time.Second)
+		notifyCountdown(cfg, secs)
+
+		// Wake on whole seconds before the capture.
+		next := end.Add(-time.Duration(secs-1) * time.Second)
+		if err := sleepUntil(ctx, next); err != nil {
+			return err
+		}
+	}
+}
+
+// notifyCountdown sends one countdown tick. Failures are ignored.
+func notifyCountdown(cfg config.Config, secs int) {
+	if cfg.Capture.Countdown.Cmd == "" {
+		return
+	}
+	args, err := config.RenderArgs(cfg.Capture.Countdown.Args, config.TemplateData{
+		Title: "OreGo",
+		Body:  fmt.Sprintf("Capturing in %ds", secs),
+	})
+	if err != nil {
+		return
+	}
+	exec.Command(cfg.Capture.Countdown.Cmd, args...).Run()
+}
+
+// waitForFocus polls the active window until its class matches pattern.
+func waitForFocus(ctx context.Context, pattern *regexp.Regexp, deadline time.Time) error {
+	for {
+		win, err := hyprland.ActiveWindow()
+		if err != nil {
+			return err
+		}
+		if win.Class != "" && pattern.MatchString(win.Class) {
+			return nil
+		}
+
+		next := time.Now().Add(focusPollInterval)
+		if !deadline.IsZero() && next.After(deadline) {
+			return errUntilReached
+		}
+		if err := sleepUntil(ctx, next); err != nil {
+			return err
+		}
+	}
+}
+
+// runTimelapse captures a frame every interval until --count frames were
+// taken, the --until deadline passes or the user interrupts it. Frames go
+// straight to disk and are grouped in a session.
+func runTimelapse(ctx context.Context, cmd *cobra.Command, cfg config.Config, appRules []apprules.Rule, deadline time.Time) {
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	name := sessionName
+	if name == "" {
+		name = time.Now().Format("timelapse-2006-01-02_15-04-05")
+	}
+	sessionID, err := store.StartSession(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	albums, err := captureAlbums(store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Recording session %q every %s (Ctrl+C to stop)\n", name, interval)
+
+	stages := []config.StageConfig{{Type: pipeline.StageNone}}
+	start := time.Now()
+	saved := 0
+	for frame := 0; count == 0 || frame < count; frame++ {
+		at := start.Add(time.Duration(frame) * interval)
+		if !deadline.IsZero() && at.After(deadline) {
+			break
+		}
+		if err := sleepUntil(ctx, at); err != nil {
+			break
+		}
+
+		// A failed frame is reported and skipped so that one bad grab
+		// does not end a long session.
+		data, rule, err := captureContext(appRules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Frame %d skipped: %v\n", frame+1, err)
+			continue
+		}
+		frameCfg := cfg
+		if rule != nil {
+			frameCfg = rule.Apply(cfg)
+		}
+
+		tmpPath, err := grabScreenshot(cmd, frameCfg, data, true)
+		var blocked errCaptureBlocked
+		if errors.As(err, &blocked) {
+			fmt.Fprintf(os.Stderr, "Frame %d skipped: blocked by privacy rule %q.\n", frame+1, string(blocked))
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Frame %d skipped: %v\n", frame+1, err)
+			continue
+		}
+
+		data.SessionID = sessionID
+		data.Session = name
+		data.Albums = albums
+		_, err = saveScreenshot(store, data, tmpPath, stages, frameCfg.Capture.Editor, frameCfg.Storage)
+		os.Remove(tmpPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Frame %d skipped: %v\n", frame+1, err)
+			continue
+		}
+		saved++
+		fmt.Printf("Frame %d: screenshot %d\n", frame+1, data.ID)
+
+		if err := hooks.Run(hooks.PostSave, frameCfg.Hooks.PostSave, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Frame %d: %v\n", frame+1, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Session %q: %d frames saved.\n", name, saved)
+}
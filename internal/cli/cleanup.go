@@ -3,10 +3,9 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"orego/internal/db"
+	"orego/internal/config"
 )
 
 var cleanupCmd = &cobra.Command{
@@ -20,14 +19,13 @@ func init() {
 }
 
 func runCleanup(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
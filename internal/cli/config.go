@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"orego/internal/apprules"
+	"orego/internal/config"
+	"orego/internal/hooks"
+	"orego/internal/imaging"
+	"orego/internal/pipeline"
+	"orego/internal/privacy"
+	"orego/internal/retention"
+)
+
+var (
+	configProfile   string
+	configEffective bool
+	configFormat    string
+	configForce     bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check, show or create the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config files, environment overrides and templates",
+	Run:   runConfigValidate,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List the config sources, or print the effective config",
+	Run:   runConfigShow,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the default config to the config directory",
+	Run:   runConfigInit,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configProfile, "profile", "", "Apply the config profile profiles/<name>.* (default $OREGO_PROFILE)")
+	cobra.OnInitialize(func() { config.SetProfile(configProfile) })
+
+	configShowCmd.Flags().BoolVar(&configEffective, "effective", false, "Print the merged config instead of its sources")
+	configShowCmd.Flags().StringVar(&configFormat, "format", "toml", "Output format for --effective (toml, yaml, json)")
+	configInitCmd.Flags().StringVar(&configFormat, "format", "toml", "File format (toml, yaml, json)")
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite an existing config file")
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configInitCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	loaded, err := config.LoadAll()
+	if err == nil {
+		err = checkConfig(loaded.Config)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Config OK (%d files, %d environment overrides).\n", len(loaded.Files), len(loaded.Env))
+}
+
+// checkConfig runs the checks the commands using each section would run
+// before doing any work.
+func checkConfig(cfg config.Config) error {
+	var errs []error
+	if _, err := imaging.NormalizeFormat(cfg.Storage.Format); err != nil {
+		errs = append(errs, fmt.Errorf("storage.format: %w", err))
+	}
+	if err := pipeline.Validate(cfg.Capture.Pipeline); err != nil {
+		errs = append(errs, fmt.Errorf("capture.pipeline: %w", err))
+	}
+	if err := hooks.Validate(cfg.Hooks); err != nil {
+		errs = append(errs, fmt.Errorf("hooks: %w", err))
+	}
+	if _, err := apprules.Compile(cfg.AppRules); err != nil {
+		errs = append(errs, fmt.Errorf("app_rules: %w", err))
+	}
+	if _, err := privacy.CompileRules(cfg.Privacy.Rules); err != nil {
+		errs = append(errs, fmt.Errorf("privacy.rules: %w", err))
+	}
+	if _, err := retention.NewPolicy(cfg.Retention); err != nil {
+		errs = append(errs, fmt.Errorf("retention: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) {
+	loaded, err := config.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if configEffective {
+		data, err := config.Encode(loaded.Config, configFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding config: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Config directory: %s\n", dir)
+	if loaded.Profile != "" {
+		fmt.Printf("Profile: %s\n", loaded.Profile)
+	}
+	fmt.Println("Files (later ones win):")
+	if len(loaded.Files) == 0 {
+		fmt.Println("  none, using defaults")
+	}
+	for _, f := range loaded.Files {
+		fmt.Printf("  %s\n", f)
+	}
+	if len(loaded.Env) > 0 {
+		fmt.Println("Environment:")
+		for _, name := range loaded.Env {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) {
+	data, err := config.Encode(config.Default(), configFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding config: %v\n", err)
+		os.Exit(1)
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config: %v\n", err)
+		os.Exit(1)
+	}
+	path := filepath.Join(dir, "config."+configFormat)
+	existing, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(existing); err == nil {
+		if existing != path {
+			fmt.Fprintf(os.Stderr, "Config already exists at %s; remove it to switch formats.\n", existing)
+			os.Exit(1)
+		}
+		if !configForce {
+			fmt.Fprintf(os.Stderr, "Config already exists at %s (use --force to overwrite).\n", existing)
+			os.Exit(1)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
@@ -3,72 +3,77 @@ package cli
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"orego/internal/db"
+	"orego/internal/actions"
+	"orego/internal/config"
+)
+
+var (
+	copyQuery string
+	copyPaths bool
 )
 
 var copyCmd = &cobra.Command{
-	Use:   "copy [id]",
-	Short: "Copy a screenshot to the clipboard",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-		}
-		return nil
-	},
-	Run: runCopy,
+	Use:   "copy [id|range|list]...",
+	Short: "Copy a screenshot (or the paths of several) to the clipboard",
+	Args:  selectorArgs,
+	Run:   runCopy,
 }
 
 func init() {
+	addSelectorFlags(copyCmd, &copyQuery)
+	copyCmd.Flags().BoolVarP(&copyPaths, "paths", "p", false, "Copy newline-separated file paths instead of the image")
 	rootCmd.AddCommand(copyCmd)
 }
 
 func runCopy(cmd *cobra.Command, args []string) {
-	id, err := strconv.ParseInt(args[0], 10, 64)
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
-		os.Exit(1)
-	}
-
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	path, err := store.GetScreenshotPath(id)
+	ids, err := resolveSelection(store, args, copyQuery)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	file, err := os.Open(path)
+	if len(ids) > 1 && !copyPaths {
+		fmt.Fprintf(os.Stderr, "Error: %d screenshots selected but the clipboard holds one image; use --paths to copy their paths\n", len(ids))
+		os.Exit(1)
+	}
+
+	paths, err := existingPaths(store, ids)
 	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "File no longer exists: %s\n", path)
-			fmt.Println("Tip: Run 'orego cleanup' to remove stale records.")
+		os.Exit(1)
+	}
+
+	if copyPaths {
+		if err := actions.CopyText(cfg, strings.Join(paths, "\n")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
+		fmt.Printf("Copied %d path(s) to clipboard.\n", len(paths))
+		return
+	}
+
+	sc, err := store.GetScreenshot(ids[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer file.Close()
 
-	copyCmd := exec.Command("wl-copy", "--type", "image/png")
-	copyCmd.Stdin = file
-	if err := copyCmd.Run(); err != nil {
+	if err := actions.CopyImage(cfg, paths[0], actions.MimeType(sc)); err != nil {
 		fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
 		os.Exit(1)
 	}
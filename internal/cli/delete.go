@@ -3,65 +3,56 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strconv"
 
 	"github.com/spf13/cobra"
-	"orego/internal/db"
+	"orego/internal/config"
 )
 
+var deleteQuery string
+
 var deleteCmd = &cobra.Command{
-	Use:   "delete [id]",
-	Short: "Delete a screenshot by ID",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-		}
-		return nil
-	},
-	Run: runDelete,
+	Use:   "delete [id|range|list]...",
+	Short: "Delete screenshots by ID, range or query",
+	Example: `  orego delete 42
+  orego delete 10-15 18,21
+  orego delete --query app=firefox`,
+	Args: selectorArgs,
+	Run:  runDelete,
 }
 
 func init() {
+	addSelectorFlags(deleteCmd, &deleteQuery)
 	rootCmd.AddCommand(deleteCmd)
 }
 
 func runDelete(cmd *cobra.Command, args []string) {
-	id, err := strconv.ParseInt(args[0], 10, 64)
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
-		os.Exit(1)
-	}
-
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	path, err := store.GetScreenshotPath(id)
+	ids, err := resolveSelection(store, args, deleteQuery)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error deleting file: %v\n", err)
+	if err := store.DeleteScreenshots(ids); err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting screenshots: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := store.DeleteScreenshot(id); err != nil {
-		fmt.Fprintf(os.Stderr, "Error deleting record: %v\n", err)
-		os.Exit(1)
+	if len(ids) == 1 {
+		fmt.Printf("Deleted screenshot %d\n", ids[0])
+		return
 	}
-
-	fmt.Printf("Deleted screenshot %d\n", id)
+	fmt.Printf("Deleted %d screenshots\n", len(ids))
 }
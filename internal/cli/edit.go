@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/imaging"
+	"orego/internal/pipeline"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+var (
+	editTimeout    time.Duration
+	revertRevision int
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [id]",
+	Short: "Annotate a saved screenshot again, keeping the original as a revision",
+	Args:  cobra.ExactArgs(1),
+	Run:   runEdit,
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert [id]",
+	Short: "Switch a screenshot back to an earlier revision",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRevert,
+}
+
+func init() {
+	editCmd.Flags().DurationVar(&editTimeout, "timeout", 5*time.Second, "Max time to wait for the editor's output after it closes")
+	revertCmd.Flags().IntVar(&revertRevision, "to", -1, "Revision number to restore (default: the one before the current)")
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(revertCmd)
+}
+
+func runEdit(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	sc, err := store.GetScreenshot(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if sc.IsVideo() {
+		fmt.Fprintln(os.Stderr, "Recordings cannot be edited.")
+		os.Exit(1)
+	}
+
+	source, err := vault.PlainPath(sc.FilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(source); err != nil {
+		fmt.Fprintf(os.Stderr, "File no longer exists: %s\n", sc.FilePath)
+		os.Exit(1)
+	}
+
+	next := 1
+	for _, r := range sc.Revisions {
+		next = max(next, r.Number+1)
+	}
+	targetPath := revisionPath(sc.FilePath, next)
+
+	// The editor writes PNG; the revision keeps the original's format so
+	// the stored MIME type stays valid.
+	editorOut := filepath.Join(os.TempDir(), fmt.Sprintf("orego-edit-%d.png", time.Now().UnixNano()))
+	defer os.Remove(editorOut)
+
+	fmt.Println("Opening editor... (Waiting for you to save and close the window)")
+	_, err = pipeline.Run([]config.StageConfig{{Type: pipeline.StageEditor}}, source, editorOut, pipeline.Options{
+		Editor:     cfg.Capture.Editor,
+		Screenshot: sc,
+		Timeout:    editTimeout,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+	})
+	if errors.Is(err, pipeline.ErrDiscarded) {
+		fmt.Fprintln(os.Stderr, "Edit discarded.")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
+		os.Exit(1)
+	}
+	if err := encodeForStorage(cfg.Storage, imaging.FormatOf(targetPath), editorOut, targetPath, sc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving revision: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The editor may have cropped or resized the image.
+	rev := models.Revision{MimeType: imaging.MimeType(targetPath)}
+	if rev.Width, rev.Height, err = imaging.Dimensions(editorOut); err != nil {
+		os.Remove(targetPath)
+		fmt.Fprintf(os.Stderr, "Error saving revision: %v\n", err)
+		os.Exit(1)
+	}
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving revision: %v\n", err)
+		os.Exit(1)
+	}
+	rev.Size = info.Size()
+
+	if vault.IsEncrypted(sc.FilePath) {
+		v, err := vault.Current()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading vault: %v\n", err)
+			os.Exit(1)
+		}
+		if targetPath, err = v.EncryptFile(targetPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encrypting screenshot: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	rev.FilePath = targetPath
+	revision, err := store.AddRevision(id, rev)
+	if err != nil {
+		os.Remove(targetPath)
+		fmt.Fprintf(os.Stderr, "Error saving revision: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved revision %d of screenshot %d: %s\n", revision, id, targetPath)
+}
+
+func runRevert(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	revisions, err := store.ListRevisions(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(revisions) == 0 {
+		fmt.Fprintf(os.Stderr, "Screenshot %d has never been edited.\n", id)
+		os.Exit(1)
+	}
+
+	target := revertRevision
+	if target < 0 {
+		for _, r := range revisions {
+			if r.Current {
+				target = r.Number - 1
+			}
+		}
+		if target < 0 {
+			fmt.Fprintf(os.Stderr, "Screenshot %d is already at its original revision.\n", id)
+			os.Exit(1)
+		}
+	}
+
+	path, err := store.SetCurrentRevision(id, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Screenshot %d reverted to revision %d: %s\n", id, target, path)
+}
+
+// revisionPath derives the file name for revision n from the current
+// file, e.g. shot.png -> shot_r2.png (shot_r2.png.age in the vault).
+func revisionPath(current string, n int) string {
+	plain := vault.PlainName(current)
+	ext := filepath.Ext(plain)
+	base := strings.TrimSuffix(plain, ext)
+	if i := strings.LastIndex(base, "_r"); i >= 0 {
+		if _, err := strconv.Atoi(base[i+2:]); err == nil {
+			base = base[:i]
+		}
+	}
+	return fmt.Sprintf("%s_r%d%s", base, n, ext)
+}
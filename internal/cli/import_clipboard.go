@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/hooks"
+	"orego/internal/imaging"
+	"orego/internal/pipeline"
+	"orego/internal/privacy"
+	"orego/pkg/hyprland"
+	"orego/pkg/models"
+)
+
+// clipboardImageTypes are the clipboard types import-clipboard accepts,
+// in order of preference.
+var clipboardImageTypes = []string{"image/png", "image/jpeg"}
+
+var importClipboardCmd = &cobra.Command{
+	Use:   "import-clipboard",
+	Short: "Save the image on the clipboard with the current window context",
+	Args:  cobra.NoArgs,
+	Run:   runImportClipboard,
+}
+
+func init() {
+	importClipboardCmd.Flags().StringVar(&captureAlbum, "album", "", "Add the image to this album (default: the current album)")
+	rootCmd.AddCommand(importClipboardCmd)
+}
+
+func runImportClipboard(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := imaging.NormalizeFormat(cfg.Storage.Format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in storage config: %v\n", err)
+		os.Exit(1)
+	}
+
+	tmpPath, err := pasteImage()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.Remove(tmpPath)
+
+	data, err := hyprland.GetScreenshotData(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching data: %v\n", err)
+		os.Exit(1)
+	}
+	data.Capture.Command = "orego import-clipboard"
+	data.Origin = models.OriginClipboard
+	// The image did not come from the screen, so there is no region.
+	data.Region = nil
+	data.Scale = 0
+
+	// Privacy rules cannot blur pasted pixels, but a matching window must
+	// not leak its titles into the record.
+	privacyRules, err := privacy.CompileRules(cfg.Privacy.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading privacy rules: %v\n", err)
+		os.Exit(1)
+	}
+	if decision := privacy.Evaluate(privacyRules, data); decision.BlockedBy != "" || decision.NoMetadata {
+		privacy.StripMetadata(data)
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if data.Albums, err = captureAlbums(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stages := []config.StageConfig{{Type: pipeline.StageNone}}
+	if _, err := saveScreenshot(store, data, tmpPath, stages, cfg.Capture.Editor, cfg.Storage); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(data)
+
+	if err := hooks.Run(hooks.PostSave, cfg.Hooks.PostSave, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// pasteImage writes the clipboard image to a temp PNG and returns its
+// path. JPEG data is converted, the capture pipeline expects PNG input.
+func pasteImage() (string, error) {
+	out, err := exec.Command("wl-paste", "--list-types").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list clipboard types: %w", err)
+	}
+	offered := strings.Fields(string(out))
+
+	mimeType := ""
+	for _, want := range clipboardImageTypes {
+		for _, t := range offered {
+			if t == want {
+				mimeType = want
+				break
+			}
+		}
+		if mimeType != "" {
+			break
+		}
+	}
+	if mimeType == "" {
+		return "", fmt.Errorf("clipboard holds no image (supported: %s)", strings.Join(clipboardImageTypes, ", "))
+	}
+
+	raw, err := exec.Command("wl-paste", "--no-newline", "--type", mimeType).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	if len(raw) == 0 {
+		return "", fmt.Errorf("clipboard image is empty")
+	}
+
+	tmpFile, err := os.CreateTemp("", "orego-clip-*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	_, err = tmpFile.Write(raw)
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if mimeType != "image/png" {
+		// Encode reads src fully before writing, so converting in place is safe.
+		if err := imaging.Encode(tmpPath, tmpPath, imaging.FormatPNG, 0); err != nil {
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("failed to convert clipboard image: %w", err)
+		}
+	}
+	return tmpPath, nil
+}
@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"orego/internal/config"
 	"orego/internal/db"
+	"orego/internal/search"
 	"orego/internal/tui"
+	"orego/pkg/models"
 )
 
 var (
@@ -16,17 +21,21 @@ var (
 	filterValue string
 	useTui      bool
 	useTv       bool
+	listAlbum   string
+	listFuzzy   bool
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [query]",
 	Short: "List recent screenshots or open TUI",
 	Run:   runList,
 }
 
 func init() {
-	listCmd.Flags().StringVar(&filterField, "filter-by", "", "Field to filter by (app, title)")
+	listCmd.Flags().StringVar(&filterField, "filter-by", "", "Field to filter by (app, title, session, album, origin)")
 	listCmd.Flags().StringVar(&filterValue, "value", "", "Value to search for")
+	listCmd.Flags().StringVar(&listAlbum, "album", "", "Only show screenshots in this album")
+	listCmd.Flags().BoolVar(&listFuzzy, "fuzzy", false, "Search titles, apps, file names and background windows (default when a query is given without --filter-by)")
 	listCmd.Flags().BoolVar(&useTui, "tui", false, "Open interactive TUI")
 	listCmd.Flags().BoolVar(&useTv, "tv", false, "Output tab-separated rows for television")
 	rootCmd.AddCommand(listCmd)
@@ -34,17 +43,21 @@ func init() {
 
 func runList(cmd *cobra.Command, args []string) {
 	if filterValue == "" && len(args) > 0 {
-		filterValue = args[0]
+		filterValue = strings.Join(args, " ")
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
-		os.Exit(1)
+	// Only the TUI deletes or opens files, so other listings work even
+	// with a broken config.
+	var cfg config.Config
+	if useTui {
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
@@ -52,7 +65,7 @@ func runList(cmd *cobra.Command, args []string) {
 	defer store.Close()
 
 	if useTv {
-		screenshots, err := store.ListScreenshots(0, "", "")
+		screenshots, err := store.FindScreenshots(db.ListOptions{Album: listAlbum})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
 			os.Exit(1)
@@ -70,19 +83,57 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 
 	if useTui {
-		if err := tui.RenderTable(store); err != nil {
+		if err := tui.RenderTable(store, cfg, listAlbum); err != nil {
 			fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
-	screenshots, err := store.ListScreenshots(50, filterField, filterValue)
+	// A query without --filter-by is searched like the TUI and Tarragon
+	// do. Date phrases ("yesterday", "in march") also narrow --filter-by
+	// when other text is left to filter on.
+	if listFuzzy || (filterField == "" && filterValue != "") {
+		screenshots, err := store.FindScreenshots(db.ListOptions{Album: listAlbum, WithClients: true})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
+			os.Exit(1)
+		}
+		screenshots = search.Screenshots(screenshots, filterValue, time.Now())
+		printScreenshotTable(screenshots[:min(len(screenshots), 50)])
+		return
+	}
+
+	// A value that is only a date phrase is taken literally, so a title
+	// search for "friday" still finds "friday".
+	query := search.ParseQuery(filterValue, time.Now())
+	if query.Text == "" {
+		query = search.Query{Text: filterValue}
+	}
+	opts := db.ListOptions{
+		Limit: 50,
+		Field: filterField,
+		Value: query.Text,
+		Album: listAlbum,
+	}
+	if query.Range != nil {
+		opts.Limit = 0
+	}
+	screenshots, err := store.FindScreenshots(opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
 		os.Exit(1)
 	}
+	if query.Range != nil {
+		screenshots = search.InRange(screenshots, *query.Range)
+		screenshots = screenshots[:min(len(screenshots), 50)]
+	}
+
+	printScreenshotTable(screenshots)
+}
 
+// printScreenshotTable writes the standard list table to stdout.
+func printScreenshotTable(screenshots []models.Screenshot) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	fmt.Fprintln(w, "ID\tTIME\tAPP\tTITLE\tFILE")
 	for _, sc := range screenshots {
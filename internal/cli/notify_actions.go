@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"orego/internal/actions"
+	"orego/internal/config"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+// notifyActionLabels are the buttons offered after a capture, keyed by
+// the action name used in capture.actions.actions.
+var notifyActionLabels = map[string]string{
+	actions.Open:       "Open",
+	actions.Copy:       "Copy",
+	actions.CopyPath:   "Copy path",
+	actions.OpenFolder: "Open folder",
+	actions.OCRCopy:    "Copy text",
+	actions.Delete:     "Delete",
+}
+
+var (
+	notifyActionsTitle string
+	notifyActionsBody  string
+)
+
+// notifyActionsCmd shows the notification with actions for a saved
+// screenshot. Capture and record start it detached so they can return
+// while the notification is still open.
+var notifyActionsCmd = &cobra.Command{
+	Use:    "notify-actions <id>",
+	Short:  "Show the notification with actions for a screenshot (started by capture)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	Run:    runNotifyActions,
+}
+
+func init() {
+	notifyActionsCmd.Flags().StringVar(&notifyActionsTitle, "title", "", "Notification title")
+	notifyActionsCmd.Flags().StringVar(&notifyActionsBody, "body", "", "Notification body")
+	rootCmd.AddCommand(notifyActionsCmd)
+}
+
+func runNotifyActions(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid ID %q\n", args[0])
+		os.Exit(1)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	sc, err := store.GetScreenshot(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	notifyWithActions(actions.Env{Store: store, Config: cfg}, sc, notifyActionsTitle, notifyActionsBody)
+}
+
+// notifyInBackground runs notifyWithActions for sc in a detached orego
+// process, so the caller does not wait for the notification to close.
+func notifyInBackground(cfg config.Config, sc *models.Screenshot, title, body string) {
+	if !cfg.Capture.Actions.Enabled || cfg.Capture.Actions.Cmd == "" {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating orego binary: %v\n", err)
+		return
+	}
+	args := []string{"notify-actions", strconv.FormatInt(sc.ID, 10), "--title", title, "--body", body}
+	if profile := config.Profile(); profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	notify := exec.Command(exe, args...)
+	notify.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := notify.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error showing notification: %v\n", err)
+		return
+	}
+	notify.Process.Release()
+}
+
+// notifyWithActions announces a saved capture with action buttons and
+// blocks until the notification is closed, running the chosen action.
+// Failures are reported on stderr; the capture itself already succeeded.
+func notifyWithActions(env actions.Env, sc *models.Screenshot, title, body string) {
+	cfg := env.Config.Capture.Actions
+	if !cfg.Enabled || cfg.Cmd == "" {
+		return
+	}
+
+	data := config.NewTemplateData(sc)
+	data.Title, data.Body = title, body
+	args, err := config.RenderArgs(cfg.Args, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering notification args: %v\n", err)
+		return
+	}
+	for _, action := range cfg.Actions {
+		label, ok := notifyActionLabels[action]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Ignoring unknown notification action %q\n", action)
+			continue
+		}
+		args = append(args, fmt.Sprintf("--action=%s=%s", action, label))
+	}
+
+	out, err := exec.Command(cfg.Cmd, args...).Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error showing notification: %v\n", err)
+		return
+	}
+
+	// Dismissed or expired notifications print nothing.
+	action := strings.TrimSpace(string(out))
+	if action == "" {
+		return
+	}
+	res, err := actions.Run(action, env, sc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running action %q: %v\n", action, err)
+		return
+	}
+	if action == actions.Delete {
+		fmt.Fprintf(os.Stderr, "%s.\n", res.Message)
+	}
+}
+
+// captureSummary is the notification body for a saved capture.
+func captureSummary(sc *models.Screenshot) string {
+	switch {
+	case sc.ActiveWindow.Class != "" && sc.ActiveWindow.Title != "":
+		return sc.ActiveWindow.Class + " - " + sc.ActiveWindow.Title
+	case sc.ActiveWindow.Class != "":
+		return sc.ActiveWindow.Class
+	default:
+		return filepath.Base(vault.PlainName(sc.FilePath))
+	}
+}
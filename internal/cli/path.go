@@ -3,65 +3,44 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strconv"
 
 	"github.com/spf13/cobra"
-	"orego/internal/db"
+	"orego/internal/config"
 )
 
+var pathQuery string
+
 var pathCmd = &cobra.Command{
-	Use:   "path [id]",
-	Short: "Print the full path to a screenshot",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-		}
-		return nil
-	},
-	Run: runPath,
+	Use:   "path [id|range|list]...",
+	Short: "Print the full path to screenshots",
+	Args:  selectorArgs,
+	Run:   runPath,
 }
 
 func init() {
+	addSelectorFlags(pathCmd, &pathQuery)
 	rootCmd.AddCommand(pathCmd)
 }
 
 func runPath(cmd *cobra.Command, args []string) {
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
-		os.Exit(1)
-	}
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
-		os.Exit(1)
-	}
-
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(config.Config{})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	path, err := store.GetScreenshotPath(id)
+	ids, err := resolveSelection(store, args, pathQuery)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if _, err := os.Stat(path); err != nil {
-		if os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "File no longer exists: %s\n", path)
-			fmt.Println("Tip: Run 'orego cleanup' to remove stale records.")
-			os.Exit(1)
-		}
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+	paths, err := existingPaths(store, ids)
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
-
-	fmt.Println(path)
 }
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/retention"
+	"orego/internal/vault"
+)
+
+var pruneDryRun bool
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete screenshots according to the retention policy",
+	Run:   runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().BoolVarP(&pruneDryRun, "dry-run", "n", false, "Only report what would be removed")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	policy, err := retention.NewPolicy(cfg.Retention)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Titles are sealed in the vault and read as a placeholder while it is
+	// locked, so keep_titles would protect nothing.
+	if len(cfg.Retention.KeepTitles) > 0 {
+		if v, err := vault.Current(); err == nil && v != nil && !v.Unlocked() {
+			fmt.Fprintf(os.Stderr, "Error: keep_titles needs the window titles: %v\n", vault.ErrLocked)
+			os.Exit(1)
+		}
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	screenshots, err := store.ListScreenshots(0, "", "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
+		os.Exit(1)
+	}
+
+	items := make([]retention.Item, 0, len(screenshots))
+	for _, sc := range screenshots {
+		item := retention.Item{Screenshot: sc}
+		if info, err := os.Stat(sc.FilePath); err == nil {
+			item.Size = info.Size()
+		}
+		items = append(items, item)
+	}
+
+	decisions := policy.Evaluate(items, time.Now())
+	if len(decisions) == 0 {
+		if policy.Empty() {
+			fmt.Println("No retention rules configured. Add a \"retention\" section to your config.")
+			return
+		}
+		fmt.Println("Nothing to prune.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tAPP\tSIZE\tREASON")
+	var freed int64
+	removed := 0
+	for _, d := range decisions {
+		sc := d.Screenshot
+		if !pruneDryRun {
+			if err := store.DeleteScreenshot(sc.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting screenshot %d: %v\n", sc.ID, err)
+				continue
+			}
+		}
+		removed++
+		freed += d.Size
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			sc.ID,
+			sc.Capture.Ts.Local().Format("2006-01-02 15:04"),
+			sc.ActiveWindow.Class,
+			retention.FormatSize(d.Size),
+			d.Reason,
+		)
+	}
+	w.Flush()
+
+	if pruneDryRun {
+		fmt.Printf("Would remove %d screenshots (%s).\n", removed, retention.FormatSize(freed))
+	} else {
+		fmt.Printf("Removed %d screenshots (%s freed).\n", removed, retention.FormatSize(freed))
+	}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/hooks"
+	"orego/internal/imaging"
+	"orego/internal/privacy"
+	"orego/internal/recorder"
+	"orego/internal/vault"
+	"orego/pkg/hyprland"
+	"orego/pkg/models"
+)
+
+var (
+	recordRegion   string
+	recordOutput   string
+	recordNoNotify bool
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the screen, with the same metadata as screenshots",
+}
+
+var recordStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start recording the focused monitor or a region",
+	Args:  cobra.NoArgs,
+	Run:   runRecordStart,
+}
+
+var recordStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the recording and save it",
+	Args:  cobra.NoArgs,
+	Run:   runRecordStop,
+}
+
+var recordStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether a recording is in progress",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		st, err := recorder.Load()
+		if errors.Is(err, recorder.ErrNotRecording) {
+			fmt.Println("Not recording.")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !recorder.Alive(st.Pid) {
+			fmt.Printf("Recorder exited; run 'orego record stop' to save %s.\n", st.Output)
+			return
+		}
+		fmt.Printf("Recording %s for %s.\n", st.Output, time.Since(st.Started).Round(time.Second))
+	},
+}
+
+func init() {
+	recordStartCmd.Flags().StringVar(&recordRegion, "region", "", "Record only this region, e.g. \"$(slurp)\" (X,Y WxH)")
+	recordStartCmd.Flags().StringVarP(&recordOutput, "output", "o", "", "Write the recording to this file")
+	recordStopCmd.Flags().BoolVar(&recordNoNotify, "no-notify", false, "Skip the notification with actions after saving")
+	recordCmd.AddCommand(recordStartCmd)
+	recordCmd.AddCommand(recordStopCmd)
+	recordCmd.AddCommand(recordStatusCmd)
+	rootCmd.AddCommand(recordCmd)
+}
+
+func runRecordStart(cmd *cobra.Command, args []string) {
+	if st, err := recorder.Load(); err == nil {
+		if recorder.Alive(st.Pid) {
+			fmt.Fprintf(os.Stderr, "Already recording to %s.\n", st.Output)
+		} else {
+			fmt.Fprintln(os.Stderr, "A previous recording was not saved. Run 'orego record stop' first.")
+		}
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := hyprland.GetScreenshotData(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching data: %v\n", err)
+		os.Exit(1)
+	}
+
+	privacyRules, err := privacy.CompileRules(cfg.Privacy.Rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading privacy rules: %v\n", err)
+		os.Exit(1)
+	}
+	decision := privacy.Evaluate(privacyRules, data)
+	if decision.BlockedBy != "" {
+		fmt.Fprintf(os.Stderr, "Recording blocked by privacy rule %q.\n", decision.BlockedBy)
+		os.Exit(1)
+	}
+	// Windows cannot be blurred in a live recording, so refuse instead.
+	if len(decision.Blur) > 0 {
+		fmt.Fprintln(os.Stderr, "Recording blocked: a blur-window privacy rule matches a visible window.")
+		os.Exit(1)
+	}
+	if decision.NoMetadata {
+		privacy.StripMetadata(data)
+	}
+	if err := hooks.Run(hooks.PreCapture, cfg.Hooks.PreCapture, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Recording cancelled: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputPath := recordOutput
+	if outputPath == "" {
+		dir, err := mediaDir(cfg.Record.Dir, "Videos", "Recordings")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ext := strings.TrimPrefix(cfg.Record.Extension, ".")
+		outputPath = filepath.Join(dir, fmt.Sprintf("%s_orego.%s", time.Now().Format("2006-01-02_15-04-05"), ext))
+	}
+	if outputPath, err = filepath.Abs(outputPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	argsTemplate := cfg.Record.Args
+	if recordRegion != "" {
+		argsTemplate = cfg.Record.ArgsRegion
+	}
+	recData := config.NewTemplateData(data)
+	recData.Region, recData.Output = recordRegion, outputPath
+	recArgs, err := config.RenderArgs(argsTemplate, recData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering recorder args: %v\n", err)
+		os.Exit(1)
+	}
+
+	pid, err := recorder.Start(cfg.Record.Cmd, recArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	data.Capture.Command = "orego record"
+	st := &recorder.State{
+		Pid:        pid,
+		Output:     outputPath,
+		Started:    time.Now(),
+		Context:    data,
+		NoMetadata: decision.NoMetadata,
+	}
+	if err := st.Save(); err != nil {
+		recorder.Stop(pid, 5*time.Second)
+		fmt.Fprintf(os.Stderr, "Error saving recording state: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Recording to %s. Run 'orego record stop' to finish.\n", outputPath)
+}
+
+func runRecordStop(cmd *cobra.Command, args []string) {
+	st, err := recorder.Load()
+	if errors.Is(err, recorder.ErrNotRecording) {
+		fmt.Fprintln(os.Stderr, "Not recording.")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := recorder.Stop(st.Pid, 10*time.Second); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stopped := time.Now()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	info, err := os.Stat(st.Output)
+	if err != nil || info.Size() == 0 {
+		recorder.Clear()
+		logPath, _ := recorder.LogPath()
+		fmt.Fprintf(os.Stderr, "The recorder produced no file. See %s for its output.\n", logPath)
+		os.Exit(1)
+	}
+
+	data := st.Context
+	data.MediaType = models.MediaVideo
+	data.Duration = stopped.Sub(st.Started).Seconds()
+	data.Size = info.Size()
+	data.MimeType = imaging.MimeType(st.Output)
+	if !st.NoMetadata {
+		if end, err := hyprland.GetScreenshotData(false); err == nil {
+			data.EndWindow = &end.ActiveWindow
+			data.Clients = mergeClients(data.Clients, end.Clients)
+		}
+	}
+
+	posterPath := strings.TrimSuffix(st.Output, filepath.Ext(st.Output)) + ".poster.png"
+	if err := recorder.Poster(cfg.Record.Poster, st.Output, posterPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not extract a poster frame: %v\n", err)
+	} else {
+		data.PosterPath = posterPath
+	}
+
+	data.FilePath = st.Output
+	if v, err := vault.Current(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading vault: %v\n", err)
+		os.Exit(1)
+	} else if v != nil {
+		if data.FilePath, err = v.EncryptFile(data.FilePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encrypting recording: %v\n", err)
+			os.Exit(1)
+		}
+		if data.PosterPath != "" {
+			if data.PosterPath, err = v.EncryptFile(data.PosterPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encrypting poster: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	if data.Albums, err = captureAlbums(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := store.Save(data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving to DB: %v\n", err)
+		os.Exit(1)
+	}
+	if err := recorder.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not clear recording state: %v\n", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(data)
+
+	hookErr := hooks.Run(hooks.PostSave, cfg.Hooks.PostSave, data)
+
+	if !recordNoNotify {
+		notifyInBackground(cfg, data, "Recording saved", captureSummary(data))
+	}
+
+	if hookErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", hookErr)
+		os.Exit(1)
+	}
+}
+
+// mergeClients adds the windows seen at the end of a recording to those
+// seen at the start.
+func mergeClients(start, end []models.Client) []models.Client {
+	seen := make(map[string]bool, len(start))
+	for _, c := range start {
+		seen[c.Address] = true
+	}
+	for _, c := range end {
+		if !seen[c.Address] {
+			seen[c.Address] = true
+			start = append(start, c)
+		}
+	}
+	return start
+}
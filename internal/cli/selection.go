@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/db"
+	"orego/internal/hooks"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+const maxIDRange = 100000
+
+// openStore opens the default OreGo database, with sensitive columns
+// encrypted when the vault is initialized and the post_delete hooks of
+// cfg attached. Commands that never delete pass config.Config{}.
+func openStore(cfg config.Config) (*db.Store, error) {
+	dbPath, err := defaultDBPath()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := db.New(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := vault.Current()
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	if v != nil {
+		store.SetCipher(v)
+	}
+	// Deletes happen in many places, including the TUI, so post_delete
+	// hooks are attached to the store itself. They run after the delete
+	// is final, so a failing hook is only a warning.
+	if postDelete := cfg.Hooks.PostDelete; len(postDelete) > 0 {
+		store.OnDelete(func(deleted []models.Screenshot) {
+			for i := range deleted {
+				if err := hooks.Run(hooks.PostDelete, postDelete, &deleted[i]); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+		})
+	}
+	return store, nil
+}
+
+func defaultDBPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	return filepath.Join(homeDir, ".local/share/orego/orego.db"), nil
+}
+
+// addSelectorFlags registers the --query flag shared by commands that
+// operate on a selection of screenshots.
+func addSelectorFlags(cmd *cobra.Command, query *string) {
+	cmd.Flags().StringVarP(query, "query", "q", "", "Select screenshots matching a list filter (e.g. app=firefox, title=GitHub)")
+}
+
+// selectorArgs validates that either IDs or a --query selector were given.
+func selectorArgs(cmd *cobra.Command, args []string) error {
+	query, _ := cmd.Flags().GetString("query")
+	if len(args) == 0 && strings.TrimSpace(query) == "" {
+		return fmt.Errorf("requires at least one ID or --query")
+	}
+	return nil
+}
+
+// existingPaths returns the file paths of the given screenshots, reporting
+// records whose file is gone on stderr. The returned error is non-nil if
+// any file was missing or unreadable.
+func existingPaths(store *db.Store, ids []int64) ([]string, error) {
+	paths := make([]string, 0, len(ids))
+	missing := 0
+	for _, id := range ids {
+		path, err := store.GetScreenshotPath(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			missing++
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "File no longer exists: %s\n", path)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			}
+			missing++
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	if missing > 0 {
+		fmt.Fprintln(os.Stderr, "Tip: Run 'orego cleanup' to remove stale records.")
+		return paths, fmt.Errorf("%d screenshot file(s) unavailable", missing)
+	}
+	return paths, nil
+}
+
+type idArg struct {
+	ID     int64
+	Ranged bool
+}
+
+// resolveSelection expands ID arguments and the --query selector into a
+// de-duplicated list of screenshot IDs, preserving the given order.
+// Explicit IDs must exist; gaps inside ranges are skipped.
+func resolveSelection(store *db.Store, args []string, query string) ([]int64, error) {
+	parsed, err := parseIDArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]int64, 0, len(parsed))
+	for _, a := range parsed {
+		candidates = append(candidates, a.ID)
+	}
+	existing, err := store.ExistingIDs(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(parsed))
+	for _, a := range parsed {
+		if !existing[a.ID] {
+			if a.Ranged {
+				continue
+			}
+			return nil, fmt.Errorf("screenshot with ID %d not found", a.ID)
+		}
+		ids = append(ids, a.ID)
+	}
+
+	if strings.TrimSpace(query) != "" {
+		field, value, err := parseQuerySelector(query)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := store.ListScreenshots(0, field, value)
+		if err != nil {
+			return nil, err
+		}
+		for _, sc := range matches {
+			ids = append(ids, sc.ID)
+		}
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("no screenshots matched")
+	}
+	return unique, nil
+}
+
+// parseIDArgs accepts IDs, comma separated lists and inclusive ranges,
+// e.g. "3", "4,7,9" or "10-15".
+func parseIDArgs(args []string) ([]idArg, error) {
+	var ids []idArg
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			if lo, hi, ok := strings.Cut(part, "-"); ok {
+				start, err := strconv.ParseInt(lo, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ID range %q", part)
+				}
+				end, err := strconv.ParseInt(hi, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid ID range %q", part)
+				}
+				if end < start {
+					return nil, fmt.Errorf("invalid ID range %q: end before start", part)
+				}
+				if end-start >= maxIDRange {
+					return nil, fmt.Errorf("invalid ID range %q: more than %d IDs", part, maxIDRange)
+				}
+				for id := start; id <= end; id++ {
+					ids = append(ids, idArg{ID: id, Ranged: true})
+				}
+				continue
+			}
+
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ID %q", part)
+			}
+			ids = append(ids, idArg{ID: id})
+		}
+	}
+	return ids, nil
+}
+
+// parseQuerySelector splits "field=value" (or "field:value") into the
+// filter pair understood by Store.ListScreenshots.
+func parseQuerySelector(query string) (string, string, error) {
+	sep := strings.IndexAny(query, "=:")
+	if sep < 0 {
+		return "", "", fmt.Errorf("invalid query %q: expected field=value", query)
+	}
+	field := strings.ToLower(strings.TrimSpace(query[:sep]))
+	value := strings.TrimSpace(query[sep+1:])
+	switch field {
+	case "app", "title", "session", "album", "origin":
+	default:
+		return "", "", fmt.Errorf("invalid query field %q (supported: app, title, session, album, origin)", field)
+	}
+	if value == "" {
+		return "", "", fmt.Errorf("invalid query %q: empty value", query)
+	}
+	return field, value, nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List capture sessions such as time-lapse sequences",
+	Run:   runSessions,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+func runSessions(cmd *cobra.Command, args []string) {
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	sessions, err := store.ListSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing sessions: %v\n", err)
+		os.Exit(1)
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions yet. Start one with 'orego capture --interval 30s'.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tFRAMES\tFIRST\tLAST")
+	for _, se := range sessions {
+		first, last := "-", "-"
+		if !se.First.IsZero() {
+			first = se.First.Local().Format("2006-01-02 15:04")
+			last = se.Last.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", se.ID, se.Name, se.Count, first, last)
+	}
+	w.Flush()
+}
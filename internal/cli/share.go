@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/actions"
+	"orego/internal/config"
+	"orego/internal/share"
+	"orego/internal/vault"
+)
+
+var (
+	shareTarget  string
+	shareNoCopy  bool
+	shareTimeout time.Duration
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share [id]",
+	Short: "Upload a screenshot and copy the link to the clipboard",
+	Args:  cobra.ExactArgs(1),
+	Run:   runShare,
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareTarget, "to", "", "Share target from the config (default: share.default)")
+	shareCmd.Flags().BoolVar(&shareNoCopy, "no-copy", false, "Do not copy the link to the clipboard")
+	shareCmd.Flags().DurationVar(&shareTimeout, "timeout", 2*time.Minute, "Max time for the upload")
+	rootCmd.AddCommand(shareCmd)
+}
+
+func runShare(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := shareTarget
+	if name == "" {
+		name = cfg.Share.Default
+	}
+	target, ok := cfg.Share.Targets[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown share target %q (configured: %s)\n", name, strings.Join(shareTargetNames(cfg), ", "))
+		os.Exit(1)
+	}
+	uploader, err := share.New(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error in share target %q: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	sc, err := store.GetScreenshot(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	path, err := vault.PlainPath(sc.FilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shareTimeout)
+	defer cancel()
+
+	link, err := uploader.Upload(ctx, share.File{
+		Path:     path,
+		Name:     filepath.Base(vault.PlainName(sc.FilePath)),
+		MimeType: actions.MimeType(sc),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error sharing to %q: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	if err := store.AddShare(id, name, link); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(link)
+
+	if !shareNoCopy {
+		if err := actions.CopyText(cfg, link); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying link to clipboard: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "Link copied to clipboard.")
+	}
+}
+
+func shareTargetNames(cfg config.Config) []string {
+	names := make([]string, 0, len(cfg.Share.Targets))
+	for name := range cfg.Share.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,59 +4,61 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"orego/internal/db"
+	"orego/internal/config"
+	"orego/pkg/models"
 )
 
+var showQuery string
+
 var showCmd = &cobra.Command{
-	Use:   "show [id]",
-	Short: "Show full details for a screenshot as JSON",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-		}
-		return nil
-	},
-	Run: runShow,
+	Use:   "show [id|range|list]...",
+	Short: "Show full details for screenshots as JSON",
+	Long:  "Show full details as JSON. A single screenshot is printed as an object, several as an array.",
+	Args:  selectorArgs,
+	Run:   runShow,
 }
 
 func init() {
+	addSelectorFlags(showCmd, &showQuery)
 	rootCmd.AddCommand(showCmd)
 }
 
 func runShow(cmd *cobra.Command, args []string) {
-	id, err := strconv.ParseInt(args[0], 10, 64)
+	store, err := openStore(config.Config{})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
+	defer store.Close()
 
-	homeDir, err := os.UserHomeDir()
+	ids, err := resolveSelection(store, args, showQuery)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
-		os.Exit(1)
+	screenshots := make([]*models.Screenshot, 0, len(ids))
+	for _, id := range ids {
+		sc, err := store.GetScreenshot(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		screenshots = append(screenshots, sc)
 	}
-	defer store.Close()
 
-	sc, err := store.GetScreenshot(id)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	var out any = screenshots
+	// Keep the single-object output for the plain "show <id>" form.
+	if len(args) == 1 && showQuery == "" && !strings.ContainsAny(args[0], ",-") {
+		out = screenshots[0]
 	}
 
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(sc); err != nil {
+	if err := enc.Encode(out); err != nil {
 		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/config"
+	"orego/internal/db"
+	"orego/internal/retention"
+	"orego/internal/search"
+	"orego/internal/stats"
+)
+
+var (
+	statsSince string
+	statsJSON  bool
+)
+
+// Table output limits: rows per ranking and the days and weeks drawn as
+// sparklines. --json has everything.
+const (
+	statsTop   = 10
+	statsDays  = 30
+	statsWeeks = 52
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show capture statistics: activity over time, top apps, storage",
+	Run:   runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "", `Only count captures since then: a duration ("30d", "2w"), a date or a phrase ("last month")`)
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the full report as JSON")
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) {
+	now := time.Now()
+	since, err := parseSince(statsSince, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	report, err := loadStats(store, since, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing screenshots: %v\n", err)
+		os.Exit(1)
+	}
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printStats(report)
+}
+
+// loadStats computes the report over every screenshot. Entries saved
+// before sizes were recorded are measured on disk.
+func loadStats(store *db.Store, since, now time.Time) (stats.Report, error) {
+	screenshots, err := store.FindScreenshots(db.ListOptions{WithClients: true})
+	if err != nil {
+		return stats.Report{}, err
+	}
+	for i := range screenshots {
+		if screenshots[i].Size == 0 {
+			if info, err := os.Stat(screenshots[i].FilePath); err == nil {
+				screenshots[i].Size = info.Size()
+			}
+		}
+	}
+	return stats.Compute(screenshots, since, now), nil
+}
+
+// parseSince accepts a duration back from now, a YYYY-MM-DD date or a
+// date phrase as understood by search queries.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := retention.ParseAge(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return t, nil
+	}
+	if q := search.ParseQuery(s, now); q.Range != nil && q.Text == "" {
+		return q.Range.From, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 30d, 2026-01-31 or \"last month\")", s)
+}
+
+func printStats(r stats.Report) {
+	if r.Total == 0 {
+		fmt.Println("No screenshots in this period.")
+		return
+	}
+
+	period := "all time"
+	if !r.Since.IsZero() {
+		period = "since " + r.Since.Format("2006-01-02 15:04")
+	}
+	fmt.Printf("%d screenshots, %s, %s\n\n", r.Total, retention.FormatSize(r.Size), period)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	days := r.Days[max(0, len(r.Days)-statsDays):]
+	fmt.Fprintf(w, "PER DAY\t%s\n", stats.Sparkline(stats.Values(days)))
+	fmt.Fprintf(w, "\t%s .. %s\n", days[0].Name, days[len(days)-1].Name)
+	fmt.Fprintf(w, "PER WEEK\t%s\n", stats.Sparkline(stats.Values(r.Weeks[max(0, len(r.Weeks)-statsWeeks):])))
+	for _, c := range r.Weeks[max(0, len(r.Weeks)-8):] {
+		fmt.Fprintf(w, "\t%s\t%d\n", c.Name, c.Count)
+	}
+	fmt.Fprintf(w, "BUSIEST HOURS\t%s\n", stats.Sparkline(r.Hours[:]))
+	fmt.Fprintf(w, "\t0     6     12    18   23\n")
+	w.Flush()
+
+	printRanking("TOP APPS", r.Apps, false)
+	printRanking("TOP WORKSPACES", r.Workspaces, false)
+	printRanking("TOP MONITORS", r.Monitors, false)
+	printRanking("STORAGE BY APP", r.Storage, true)
+	printRanking("BACKGROUND APPS", r.Background, false)
+	if r.BackgroundLocked {
+		fmt.Println("\nBackground apps are incomplete while the vault is locked.")
+	}
+}
+
+func printRanking(title string, counts []stats.Count, size bool) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	if size {
+		fmt.Fprintf(w, "%s\tSIZE\tCOUNT\n", title)
+	} else {
+		fmt.Fprintf(w, "%s\tCOUNT\n", title)
+	}
+	for _, c := range stats.Top(counts, statsTop) {
+		if size {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", c.Name, retention.FormatSize(c.Size), c.Count)
+		} else {
+			fmt.Fprintf(w, "%s\t%d\n", c.Name, c.Count)
+		}
+	}
+	w.Flush()
+}
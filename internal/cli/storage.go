@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"orego/internal/config"
+	"orego/internal/imaging"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+// mediaDir resolves a configured directory ("~/" is expanded), falling
+// back to a path under the home directory, and makes sure it exists.
+func mediaDir(configured string, fallback ...string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	dir := configured
+	switch {
+	case dir == "":
+		dir = filepath.Join(append([]string{homeDir}, fallback...)...)
+	case strings.HasPrefix(dir, "~/"):
+		dir = filepath.Join(homeDir, dir[2:])
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// reserveFile creates an empty file name+ext in dir and returns its path.
+// If the name is taken, also by an encrypted file, -2, -3 and so on are
+// appended, so captures within the same second do not overwrite each
+// other.
+func reserveFile(dir, name, ext string) (string, error) {
+	for n := 1; ; n++ {
+		candidate := name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, n)
+		}
+		path := filepath.Join(dir, candidate+ext)
+		if _, err := os.Lstat(path + vault.EncryptedExt); err == nil {
+			continue
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		f.Close()
+		return path, nil
+	}
+}
+
+// encodeForStorage converts the PNG at src into format at dst, using the
+// encoder command configured for the format or the built-in encoders. sc
+// is passed to the encoder's templates and may be nil.
+func encodeForStorage(cfg config.StorageConfig, format, src, dst string, sc *models.Screenshot) error {
+	enc, ok := cfg.Encoders[format]
+	if !ok || enc.Cmd == "" {
+		return imaging.Encode(src, dst, format, cfg.Quality)
+	}
+
+	data := config.NewTemplateData(sc)
+	data.Input, data.Output, data.Quality = src, dst, cfg.Quality
+	args, err := config.RenderArgs(enc.Args, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s encoder args: %w", format, err)
+	}
+	if out, err := exec.Command(enc.Cmd, args...).CombinedOutput(); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("%s encoder failed: %w: %s", format, err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(dst); err != nil {
+		return fmt.Errorf("%s encoder did not write %s", format, dst)
+	}
+	return nil
+}
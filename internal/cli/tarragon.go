@@ -7,17 +7,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// tarragonManifestTOML is rendered with the lifecycle mode. In persistent
+// mode Tarragon keeps `orego tarragon serve` running and talks NDJSON to
+// it; on_call runs `orego tarragon query` for every keystroke.
 const tarragonManifestTOML = `name = "orego"
-description = "Search and open OreGo screenshots"
+description = "Take, search and open OreGo screenshots"
 enabled = true
 entrypoint = "orego"
-lifecycle_mode = "on_call"
-provides_general_suggestions = false
+lifecycle_mode = "%s"
+%sprovides_general_suggestions = true
 prefix = "orego "
 build_dependencies = []
 capabilities = ["suggest", "screenshot"]
 `
 
+const tarragonPersistentTOML = `persistent_args = ["tarragon", "serve"]
+protocol = "ndjson"
+`
+
+var tarragonLifecycle string
+
 var tarragonCmd = &cobra.Command{
 	Use:   "tarragon",
 	Short: "Tarragon integration helpers",
@@ -27,8 +36,16 @@ var tarragonManifestCmd = &cobra.Command{
 	Use:   "manifest",
 	Short: "Print the Tarragon plugin manifest",
 	Long:  "Print a stable Tarragon plugin manifest in TOML format to stdout.",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Fprint(cmd.OutOrStdout(), tarragonManifestTOML)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch tarragonLifecycle {
+		case "persistent":
+			fmt.Fprintf(cmd.OutOrStdout(), tarragonManifestTOML, tarragonLifecycle, tarragonPersistentTOML)
+		case "on_call":
+			fmt.Fprintf(cmd.OutOrStdout(), tarragonManifestTOML, tarragonLifecycle, "")
+		default:
+			return fmt.Errorf("invalid lifecycle %q (supported: persistent, on_call)", tarragonLifecycle)
+		}
+		return nil
 	},
 }
 
@@ -55,6 +72,7 @@ var tarragonSelectCmd = &cobra.Command{
 }
 
 func init() {
+	tarragonManifestCmd.Flags().StringVar(&tarragonLifecycle, "lifecycle", "persistent", "Lifecycle mode to advertise: persistent or on_call")
 	tarragonCmd.AddCommand(tarragonManifestCmd)
 	tarragonCmd.AddCommand(tarragonQueryCmd)
 	tarragonCmd.AddCommand(tarragonSelectCmd)
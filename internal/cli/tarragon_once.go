@@ -1,19 +1,23 @@
 package cli
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	_ "modernc.org/sqlite"
+	"orego/internal/actions"
+	"orego/internal/config"
 	"orego/internal/db"
+	"orego/internal/search"
+	"orego/internal/vault"
+	"orego/pkg/models"
 )
 
 const tarragonOnceLimit = 50
@@ -41,159 +45,225 @@ type tarragonSearchResponse struct {
 type screenshotCandidate struct {
 	ID       int64
 	Path     string
+	Preview  string // Poster frame for recordings
 	Class    string
 	Title    string
 	Score    float64
 	TieBreak int64
+
+	Screenshot *models.Screenshot // For the actions offered on the result
+	Doc        search.Doc
 }
 
 func runTarragonOnce(cmd *cobra.Command, query string) error {
-	results := searchScreenshots(strings.TrimSpace(query))
+	query = strings.TrimSpace(query)
+	results, last := searchScreenshots(query)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	items, err := tarragonResults(context.Background(), results, cfg)
+	if err != nil {
+		return err
+	}
+	items = append(tarragonSuggestions(query, last, cfg), items...)
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	return enc.Encode(tarragonSearchResponse{Results: items})
+}
 
-	resp := tarragonSearchResponse{Results: make([]tarragonResultItem, 0, len(results))}
-	for _, r := range results {
-		resp.Results = append(resp.Results, tarragonResultItem{
+// tarragonResults converts ranked candidates into result items. Preview
+// paths may need decryption, so ctx is checked between items.
+func tarragonResults(ctx context.Context, hits []screenshotCandidate, cfg config.Config) ([]tarragonResultItem, error) {
+	items := make([]tarragonResultItem, 0, len(hits))
+	for _, r := range hits {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items = append(items, tarragonResultItem{
 			ID:          strconv.FormatInt(r.ID, 10),
 			Label:       formatResultLabel(r.Class, r.Title, r.Path),
 			Description: formatResultDescription(r.Class, r.Title, r.Path),
 			Category:    "screenshots",
-			PreviewPath: r.Path,
-			Actions: []tarragonAction{
-				{Name: "open", Default: true},
-				{Name: "delete"},
-			},
-			Score: r.Score,
+			PreviewPath: previewPath(r.Preview),
+			Actions:     tarragonActions(actions.Available(r.Screenshot, cfg)),
+			Score:       r.Score,
 		})
 	}
-
-	enc := json.NewEncoder(cmd.OutOrStdout())
-	return enc.Encode(resp)
+	return items, nil
 }
 
-func runTarragonSelect(cmd *cobra.Command, resultID string, action string) error {
-	id, err := strconv.ParseInt(strings.TrimSpace(resultID), 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid result id %q: %w", resultID, err)
-	}
-
-	selectedAction := strings.TrimSpace(action)
-	if selectedAction == "" || selectedAction == "execute" {
-		selectedAction = "open"
-	}
-
-	switch selectedAction {
-	case "open":
-		if err := openScreenshotByID(id); err != nil {
-			return err
-		}
-	case "delete":
-		if err := deleteScreenshotByID(id); err != nil {
-			return err
+// tarragonActions marks open as the default, or the first action when
+// the file cannot be opened.
+func tarragonActions(names []string) []tarragonAction {
+	list := make([]tarragonAction, len(names))
+	def := 0
+	for i, name := range names {
+		list[i].Name = name
+		if name == actions.Open {
+			def = i
 		}
-	default:
-		return fmt.Errorf("unsupported action %q", selectedAction)
 	}
+	list[def].Default = true
+	return list
+}
 
-	return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
-		"ok":        true,
-		"action":    selectedAction,
-		"result_id": strconv.FormatInt(id, 10),
-	})
+// tarragonSelectReply is printed by select: the action result on
+// success, the error otherwise.
+type tarragonSelectReply struct {
+	OK       bool            `json:"ok"`
+	Action   string          `json:"action"`
+	ResultID string          `json:"result_id"`
+	Result   *actions.Result `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
 }
 
-func openScreenshotByID(id int64) error {
-	path, err := getScreenshotPathByID(id)
+func runTarragonSelect(cmd *cobra.Command, resultID string, action string) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	if _, err := os.Stat(path); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("file no longer exists: %s", path)
-		}
+	store, err := openStore(cfg)
+	if err != nil {
 		return err
 	}
-	if err := exec.Command("xdg-open", path).Start(); err != nil {
-		return fmt.Errorf("open screenshot: %w", err)
+	defer store.Close()
+
+	res, err := tarragonAct(store, cfg, resultID, action)
+	reply := tarragonSelectReply{OK: err == nil, Action: res.Action, ResultID: strings.TrimSpace(resultID)}
+	if err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.Result = &res
+	}
+	if encErr := json.NewEncoder(cmd.OutOrStdout()).Encode(reply); encErr != nil {
+		return encErr
 	}
-	return nil
+	return err
 }
 
-func deleteScreenshotByID(id int64) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
+// tarragonAct runs an action on a result; an empty action or "execute"
+// means open, or run for capture suggestions. The returned result names
+// the action even on failure.
+func tarragonAct(store *db.Store, cfg config.Config, resultID string, action string) (actions.Result, error) {
+	resultID = strings.TrimSpace(resultID)
+	selectedAction := strings.TrimSpace(action)
+	if c, ok := findTarragonCapture(resultID); ok {
+		return runTarragonCapture(c, selectedAction)
+	}
+	if selectedAction == "" || selectedAction == "execute" {
+		selectedAction = actions.Open
 	}
+	res := actions.Result{Action: selectedAction}
 
-	dbPath := filepath.Join(homeDir, ".local", "share", "orego", "orego.db")
-	store, err := db.New(dbPath)
+	var id int64
+	if resultID == tarragonLastID {
+		last, err := lastScreenshot(store)
+		if err != nil {
+			return res, err
+		}
+		if last == nil {
+			return res, fmt.Errorf("no screenshots yet")
+		}
+		id = last.ID
+	} else {
+		var err error
+		if id, err = strconv.ParseInt(resultID, 10, 64); err != nil {
+			return res, fmt.Errorf("invalid result id %q: %w", resultID, err)
+		}
+	}
+	sc, err := store.GetScreenshot(id)
 	if err != nil {
-		return err
+		return res, err
 	}
-	defer store.Close()
+	return actions.Run(selectedAction, actions.Env{Store: store, Config: cfg}, sc)
+}
 
-	if err := store.DeleteScreenshot(id); err != nil {
-		return err
+// previewPath returns a path Tarragon can render, or "" if the image is
+// encrypted and the vault is locked.
+func previewPath(path string) string {
+	plainPath, err := vault.PlainPath(path)
+	if err != nil {
+		return ""
 	}
-	return nil
+	return plainPath
 }
 
-func getScreenshotPathByID(id int64) (string, error) {
-	homeDir, err := os.UserHomeDir()
+// searchScreenshots ranks stored screenshots against query and also
+// returns the newest one scanned, for the "Last screenshot" suggestion.
+func searchScreenshots(query string) ([]screenshotCandidate, *models.Screenshot) {
+	dbPath, err := defaultDBPath()
 	if err != nil {
-		return "", err
+		return nil, nil
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, nil
 	}
 
-	dbPath := filepath.Join(homeDir, ".local", "share", "orego", "orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(config.Config{})
 	if err != nil {
-		return "", err
+		return nil, nil
 	}
 	defer store.Close()
 
-	return store.GetScreenshotPath(id)
-}
-
-func searchScreenshots(query string) []screenshotCandidate {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil
+	album, query := splitAlbumFilter(query)
+	screenshots, err := store.FindScreenshots(db.ListOptions{Limit: tarragonScanLimit(query), Album: album, WithClients: true})
+	if err != nil || len(screenshots) == 0 {
+		return nil, nil
 	}
 
-	dbPath := filepath.Join(homeDir, ".local", "share", "orego", "orego.db")
-	if _, err := os.Stat(dbPath); err != nil {
-		return nil
-	}
+	hits, _ := rankCandidates(context.Background(), toCandidates(screenshots), query)
+	return hits, &screenshots[0]
+}
 
-	dsn := fmt.Sprintf("file:%s?mode=ro", dbPath)
-	dbConn, err := sql.Open("sqlite", dsn)
-	if err != nil {
-		return nil
+func toCandidates(screenshots []models.Screenshot) []screenshotCandidate {
+	candidates := make([]screenshotCandidate, 0, len(screenshots))
+	for i := range screenshots {
+		sc := &screenshots[i]
+		c := screenshotCandidate{
+			ID:       sc.ID,
+			Path:     sc.FilePath,
+			Preview:  candidatePreview(sc),
+			Class:    sc.ActiveWindow.Class,
+			Title:    sc.ActiveWindow.Title,
+			TieBreak: sc.ID,
+
+			Screenshot: sc,
+			Doc:        search.DocOf(sc),
+		}
+		candidates = append(candidates, c)
 	}
-	defer dbConn.Close()
-
-	rows, err := dbConn.Query(`
-		SELECT id, file_path, active_window_class, active_window_title
-		FROM screenshots
-		ORDER BY id DESC
-		LIMIT ?
-	`, tarragonSearchScanLimit)
-	if err != nil {
-		return nil
+	return candidates
+}
+
+// candidatePreview is the image shown for sc: the poster frame for
+// recordings.
+func candidatePreview(sc *models.Screenshot) string {
+	if sc.IsVideo() {
+		return sc.PosterPath
 	}
-	defer rows.Close()
+	return sc.FilePath
+}
 
-	q := strings.ToLower(strings.TrimSpace(query))
+// rankCandidates scores candidates against query and returns the best
+// tarragonOnceLimit, best first. The input slice is not modified. It
+// gives up with ctx's error once ctx is done.
+func rankCandidates(ctx context.Context, candidates []screenshotCandidate, query string) ([]screenshotCandidate, error) {
+	now := time.Now()
+	q := search.ParseQuery(query, now)
 	hits := make([]screenshotCandidate, 0, tarragonOnceLimit)
 
-	for rows.Next() {
-		var c screenshotCandidate
-		if err := rows.Scan(&c.ID, &c.Path, &c.Class, &c.Title); err != nil {
-			continue
+	for i, c := range candidates {
+		if i%256 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 		}
-		c.TieBreak = c.ID
-
-		c.Score = scoreCandidate(c, q)
-		if q != "" && c.Score == 0 {
+		c.Score = q.Score(c.Doc, now)
+		if c.Score == 0 {
 			continue
 		}
 		hits = append(hits, c)
@@ -210,33 +280,31 @@ func searchScreenshots(query string) []screenshotCandidate {
 		hits = hits[:tarragonOnceLimit]
 	}
 
-	return hits
+	return hits, nil
 }
 
-func scoreCandidate(c screenshotCandidate, q string) float64 {
-	if q == "" {
-		return 1.0
+// tarragonScanLimit is how many of the newest screenshots a query looks
+// at. Queries with a date phrase scan everything, the range may be old.
+func tarragonScanLimit(query string) int {
+	if search.ParseQuery(query, time.Now()).Range != nil {
+		return 0
 	}
+	return tarragonSearchScanLimit
+}
 
-	title := strings.ToLower(c.Title)
-	class := strings.ToLower(c.Class)
-	base := strings.ToLower(filepath.Base(c.Path))
-
-	score := 0.0
-	if strings.Contains(title, q) {
-		score += 3.0
-	}
-	if strings.Contains(class, q) {
-		score += 2.0
-	}
-	if strings.Contains(base, q) {
-		score += 1.0
-	}
-	if strings.HasPrefix(title, q) {
-		score += 1.0
+// splitAlbumFilter pulls an "album:<name>" token out of a launcher query
+// and returns the album name and the remaining search text.
+func splitAlbumFilter(query string) (string, string) {
+	var album string
+	rest := make([]string, 0, 4)
+	for _, tok := range strings.Fields(query) {
+		if name, ok := strings.CutPrefix(tok, "album:"); ok && name != "" {
+			album = name
+			continue
+		}
+		rest = append(rest, tok)
 	}
-
-	return score
+	return album, strings.Join(rest, " ")
 }
 
 func formatResultLabel(class string, title string, filePath string) string {
@@ -251,7 +319,7 @@ func formatResultLabel(class string, title string, filePath string) string {
 	case cleanClass != "":
 		return cleanClass
 	default:
-		return filepath.Base(filePath)
+		return filepath.Base(vault.PlainName(filePath))
 	}
 }
 
@@ -263,6 +331,6 @@ func formatResultDescription(class string, title string, filePath string) string
 	if strings.TrimSpace(title) != "" {
 		parts = append(parts, strings.TrimSpace(title))
 	}
-	parts = append(parts, filepath.Base(filePath))
+	parts = append(parts, filepath.Base(vault.PlainName(filePath)))
 	return strings.Join(parts, " | ")
 }
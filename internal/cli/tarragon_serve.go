@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"orego/internal/actions"
+	"orego/internal/config"
+	"orego/internal/db"
+	"orego/pkg/models"
+)
+
+// tarragonRequest is one line read in persistent mode. Type is query,
+// select or cancel; cancel stops the query whose ID is given.
+type tarragonRequest struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Query    string `json:"query,omitempty"`
+	ResultID string `json:"result_id,omitempty"`
+	Action   string `json:"action,omitempty"`
+}
+
+type tarragonReply struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Action   string          `json:"action,omitempty"`
+	ResultID string          `json:"result_id,omitempty"`
+	Result   *actions.Result `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// tarragonResultsReply answers a query; results is always present.
+type tarragonResultsReply struct {
+	ID      string               `json:"id"`
+	Type    string               `json:"type"`
+	Results []tarragonResultItem `json:"results"`
+}
+
+// tarragonIndex keeps all screenshots in memory and reloads them when
+// the database files change on disk.
+type tarragonIndex struct {
+	mu         sync.Mutex
+	store      *db.Store
+	cfg        config.Config
+	dbPath     string
+	stamp      time.Time
+	candidates []screenshotCandidate
+}
+
+// dbStamp returns the newest modification time of the database and its
+// WAL, which changes whenever another process writes.
+func (ix *tarragonIndex) dbStamp() time.Time {
+	var stamp time.Time
+	for _, p := range []string{ix.dbPath, ix.dbPath + "-wal"} {
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(stamp) {
+			stamp = info.ModTime()
+		}
+	}
+	return stamp
+}
+
+// act runs a result action. The lock is only taken to drop the index
+// after a delete, so slow actions do not hold up queries.
+func (ix *tarragonIndex) act(resultID, action string) (actions.Result, error) {
+	res, err := tarragonAct(ix.store, ix.cfg, resultID, action)
+	if err == nil && res.Action == actions.Delete {
+		ix.mu.Lock()
+		ix.candidates = nil
+		ix.mu.Unlock()
+	}
+	return res, err
+}
+
+// search ranks the warm index against query and returns the newest
+// screenshot for the "Last screenshot" suggestion. Album filters are
+// rare, so they go to the database instead of being indexed.
+func (ix *tarragonIndex) search(ctx context.Context, query string) ([]screenshotCandidate, *models.Screenshot, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// A newer query may have cancelled this one while it waited.
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	album, query := splitAlbumFilter(query)
+	if album != "" {
+		screenshots, err := ix.store.FindScreenshots(db.ListOptions{Limit: tarragonScanLimit(query), Album: album, WithClients: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		hits, err := rankCandidates(ctx, toCandidates(screenshots), query)
+		return hits, nil, err
+	}
+
+	if stamp := ix.dbStamp(); ix.candidates == nil || !stamp.Equal(ix.stamp) {
+		screenshots, err := ix.store.FindScreenshots(db.ListOptions{WithClients: true})
+		if err != nil {
+			return nil, nil, err
+		}
+		ix.candidates = toCandidates(screenshots)
+		ix.stamp = stamp
+	}
+
+	// Candidates are loaded newest first.
+	var last *models.Screenshot
+	if len(ix.candidates) > 0 {
+		last = ix.candidates[0].Screenshot
+	}
+	hits, err := rankCandidates(ctx, ix.candidates, query)
+	return hits, last, err
+}
+
+var tarragonServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run as a persistent Tarragon plugin speaking NDJSON on stdin/stdout",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTarragonServe(cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	tarragonCmd.AddCommand(tarragonServeCmd)
+}
+
+// runTarragonServe answers requests until stdin is closed. Queries run in
+// the background so a cancel, or a newer query, can stop them; a newer
+// query supersedes all older ones, which then reply "cancelled".
+func runTarragonServe(in io.Reader, out io.Writer) error {
+	dbPath, err := defaultDBPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	index := &tarragonIndex{store: store, cfg: cfg, dbPath: dbPath}
+
+	var outMu sync.Mutex
+	enc := json.NewEncoder(out)
+	reply := func(r any) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		enc.Encode(r)
+	}
+
+	// Each query gets its own entry, so a finished query whose ID was
+	// reused only removes itself.
+	type inflightQuery struct {
+		cancel context.CancelFunc
+	}
+	var (
+		mu       sync.Mutex
+		inflight = make(map[string]*inflightQuery)
+		wg       sync.WaitGroup
+	)
+	cancelAll := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for id, q := range inflight {
+			q.cancel()
+			delete(inflight, id)
+		}
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req tarragonRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			reply(tarragonReply{Type: "error", Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		switch req.Type {
+		case "query":
+			cancelAll()
+			ctx, cancel := context.WithCancel(context.Background())
+			q := &inflightQuery{cancel: cancel}
+			mu.Lock()
+			inflight[req.ID] = q
+			mu.Unlock()
+
+			wg.Add(1)
+			go func(req tarragonRequest) {
+				defer wg.Done()
+				defer func() {
+					mu.Lock()
+					if inflight[req.ID] == q {
+						delete(inflight, req.ID)
+					}
+					mu.Unlock()
+					cancel()
+				}()
+
+				hits, last, err := index.search(ctx, req.Query)
+				if err == nil {
+					var items []tarragonResultItem
+					if items, err = tarragonResults(ctx, hits, cfg); err == nil && ctx.Err() == nil {
+						items = append(tarragonSuggestions(req.Query, last, cfg), items...)
+						reply(tarragonResultsReply{ID: req.ID, Type: "results", Results: items})
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					reply(tarragonReply{ID: req.ID, Type: "cancelled"})
+					return
+				}
+				reply(tarragonReply{ID: req.ID, Type: "error", Error: err.Error()})
+			}(req)
+
+		case "cancel":
+			mu.Lock()
+			q, ok := inflight[req.ID]
+			mu.Unlock()
+			if ok {
+				q.cancel()
+			}
+
+		case "select":
+			// Actions such as OCR can be slow; later requests keep flowing.
+			wg.Add(1)
+			go func(req tarragonRequest) {
+				defer wg.Done()
+				res, err := index.act(req.ResultID, req.Action)
+				resultID := strings.TrimSpace(req.ResultID)
+				if err != nil {
+					reply(tarragonReply{ID: req.ID, Type: "error", Action: res.Action, ResultID: resultID, Error: err.Error()})
+					return
+				}
+				reply(tarragonReply{ID: req.ID, Type: "selected", Action: res.Action, ResultID: resultID, Result: &res})
+			}(req)
+
+		default:
+			reply(tarragonReply{ID: req.ID, Type: "error", Error: fmt.Sprintf("unknown request type %q", req.Type)})
+		}
+	}
+
+	// Answer what was asked before stdin closed.
+	wg.Wait()
+	return scanner.Err()
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"orego/internal/actions"
+	"orego/internal/config"
+	"orego/internal/db"
+	"orego/internal/search"
+	"orego/pkg/models"
+)
+
+// tarragonSettle gives the launcher time to close before a capture it
+// started grabs the screen.
+const tarragonSettle = 400 * time.Millisecond
+
+// tarragonLastID is the result ID of the "Last screenshot" suggestion;
+// select resolves it to the newest screenshot.
+const tarragonLastID = "last"
+
+// tarragonRun is the action of capture suggestions.
+const tarragonRun = "run"
+
+// tarragonCapture is a general suggestion that starts a capture.
+type tarragonCapture struct {
+	ID          string
+	Label       string
+	Description string
+	Args        []string // Arguments to orego
+}
+
+var tarragonCaptures = []tarragonCapture{
+	{ID: "capture:screen", Label: "Capture screen", Description: "Capture the focused monitor", Args: []string{"capture"}},
+	{ID: "capture:all", Label: "Capture all monitors", Description: "Capture every visible workspace", Args: []string{"capture", "--all"}},
+	{ID: "capture:ocr", Label: "OCR to clipboard", Description: "Capture, crop and copy the recognized text", Args: []string{"capture", "--ocr"}},
+}
+
+func findTarragonCapture(id string) (tarragonCapture, bool) {
+	for _, c := range tarragonCaptures {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return tarragonCapture{}, false
+}
+
+// tarragonSuggestions returns the general suggestions matching query:
+// all of them for an empty query, none when an album is selected. last
+// is the newest screenshot, or nil if there is none.
+func tarragonSuggestions(query string, last *models.Screenshot, cfg config.Config) []tarragonResultItem {
+	album, query := splitAlbumFilter(query)
+	if album != "" {
+		return []tarragonResultItem{}
+	}
+
+	items := make([]tarragonResultItem, 0, len(tarragonCaptures)+1)
+	for _, c := range tarragonCaptures {
+		score := search.Score(search.Doc{Title: c.Label}, query, time.Time{})
+		if score == 0 {
+			continue
+		}
+		items = append(items, tarragonResultItem{
+			ID:          c.ID,
+			Label:       c.Label,
+			Description: c.Description,
+			Category:    "capture",
+			Actions:     []tarragonAction{{Name: tarragonRun, Default: true}},
+			Score:       score,
+		})
+	}
+
+	if last != nil {
+		const label = "Last screenshot"
+		if score := search.Score(search.Doc{Title: label}, query, time.Time{}); score > 0 {
+			items = append(items, tarragonResultItem{
+				ID:          tarragonLastID,
+				Label:       label,
+				Description: formatResultDescription(last.ActiveWindow.Class, last.ActiveWindow.Title, last.FilePath),
+				Category:    "screenshots",
+				PreviewPath: previewPath(candidatePreview(last)),
+				Actions:     tarragonActions(actions.Available(last, cfg)),
+				Score:       score,
+			})
+		}
+	}
+	return items
+}
+
+// lastScreenshot returns the newest screenshot, or nil if there is none.
+func lastScreenshot(store *db.Store) (*models.Screenshot, error) {
+	screenshots, err := store.FindScreenshots(db.ListOptions{Limit: 1})
+	if err != nil || len(screenshots) == 0 {
+		return nil, err
+	}
+	return &screenshots[0], nil
+}
+
+// runTarragonCapture starts the capture in its own session so it outlives
+// the launcher and the select call, which return immediately.
+func runTarragonCapture(c tarragonCapture, action string) (actions.Result, error) {
+	res := actions.Result{Action: tarragonRun}
+	if action != "" && action != "execute" && action != tarragonRun {
+		res.Action = action
+		return res, fmt.Errorf("unsupported action %q", action)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return res, fmt.Errorf("locating orego binary: %w", err)
+	}
+	args := append(append([]string{}, c.Args...), "--settle", tarragonSettle.String())
+	capture := exec.Command(exe, args...)
+	capture.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := capture.Start(); err != nil {
+		return res, fmt.Errorf("starting capture: %w", err)
+	}
+	go capture.Wait()
+
+	res.Message = c.Label + " started"
+	return res, nil
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"orego/internal/config"
+	"orego/internal/vault"
+)
+
+var (
+	vaultTTL       time.Duration
+	vaultNoMigrate bool
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Manage encryption at rest for images and window titles",
+}
+
+var vaultInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the vault key and encrypt existing screenshots",
+	Run:   runVaultInit,
+}
+
+var vaultUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Start a key agent so screenshots can be decrypted",
+	Run:   runVaultUnlock,
+}
+
+var vaultLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Stop the key agent and remove decrypted copies",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := vault.Lock(); err != nil {
+			fmt.Println("Vault was not unlocked.")
+			return
+		}
+		fmt.Println("Vault locked.")
+	},
+}
+
+var vaultStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the vault is initialized and unlocked",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !vault.Initialized() {
+			fmt.Println("Vault not initialized.")
+			return
+		}
+		expires, err := vault.AgentExpiry()
+		if err != nil {
+			fmt.Println("Vault locked.")
+			return
+		}
+		fmt.Printf("Vault unlocked until %s.\n", expires.Local().Format("15:04:05"))
+	},
+}
+
+var vaultAgentCmd = &cobra.Command{
+	Use:    "agent",
+	Short:  "Run the vault key agent (started by unlock)",
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		identity, err := age.ParseX25519Identity(strings.TrimSpace(string(raw)))
+		if err != nil {
+			return err
+		}
+		return vault.Serve(identity, vaultTTL)
+	},
+}
+
+func init() {
+	vaultInitCmd.Flags().BoolVar(&vaultNoMigrate, "no-migrate", false, "Do not encrypt screenshots saved before the vault existed")
+	vaultUnlockCmd.Flags().DurationVar(&vaultTTL, "ttl", 15*time.Minute, "Lock the vault again after this long")
+	vaultAgentCmd.Flags().DurationVar(&vaultTTL, "ttl", 15*time.Minute, "Agent lifetime")
+	vaultCmd.AddCommand(vaultInitCmd)
+	vaultCmd.AddCommand(vaultUnlockCmd)
+	vaultCmd.AddCommand(vaultLockCmd)
+	vaultCmd.AddCommand(vaultStatusCmd)
+	vaultCmd.AddCommand(vaultAgentCmd)
+	rootCmd.AddCommand(vaultCmd)
+}
+
+func runVaultInit(cmd *cobra.Command, args []string) {
+	if vault.Initialized() {
+		fmt.Fprintln(os.Stderr, "Vault already initialized.")
+		os.Exit(1)
+	}
+
+	passphrase, err := readPassphrase("New vault passphrase: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading passphrase: %v\n", err)
+		os.Exit(1)
+	}
+	if passphrase == "" {
+		fmt.Fprintln(os.Stderr, "Passphrase must not be empty.")
+		os.Exit(1)
+	}
+	confirm, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading passphrase: %v\n", err)
+		os.Exit(1)
+	}
+	if confirm != passphrase {
+		fmt.Fprintln(os.Stderr, "Passphrases do not match.")
+		os.Exit(1)
+	}
+
+	v, err := vault.Init(passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing vault: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Vault initialized. New screenshots will be encrypted.")
+
+	if vaultNoMigrate {
+		return
+	}
+
+	store, err := openStore(config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+	store.SetCipher(v)
+
+	if err := store.SealExisting(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encrypting metadata: %v\n", err)
+		os.Exit(1)
+	}
+
+	paths, err := store.ListFilePaths()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing paths: %v\n", err)
+		os.Exit(1)
+	}
+	encrypted := 0
+	for _, path := range paths {
+		if vault.IsEncrypted(path) {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		encPath, err := v.EncryptFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encrypting %s: %v\n", path, err)
+			continue
+		}
+		if err := store.ReplaceFilePath(path, encPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating records for %s: %v\n", path, err)
+			continue
+		}
+		encrypted++
+	}
+	fmt.Printf("Encrypted metadata and %d existing images.\n", encrypted)
+}
+
+func runVaultUnlock(cmd *cobra.Command, args []string) {
+	if !vault.Initialized() {
+		fmt.Fprintln(os.Stderr, "Vault not initialized. Run 'orego vault init' first.")
+		os.Exit(1)
+	}
+	if _, err := vault.AgentExpiry(); err == nil {
+		fmt.Println("Vault already unlocked.")
+		return
+	}
+
+	passphrase, err := readPassphrase("Vault passphrase: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading passphrase: %v\n", err)
+		os.Exit(1)
+	}
+	identity, err := vault.DecryptIdentity(passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating orego binary: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The identity is handed to the agent on stdin so it never shows up
+	// in argv or the environment.
+	agent := exec.Command(exe, "vault", "agent", "--ttl", vaultTTL.String())
+	agent.Stdin = strings.NewReader(identity.String())
+	agent.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := agent.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting agent: %v\n", err)
+		os.Exit(1)
+	}
+	go agent.Wait()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := vault.AgentExpiry(); err == nil {
+			fmt.Printf("Vault unlocked for %s.\n", vaultTTL)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	fmt.Fprintln(os.Stderr, "Error: agent did not start.")
+	os.Exit(1)
+}
+
+var stdinLines = bufio.NewReader(os.Stdin)
+
+// readPassphrase prompts on the terminal without echo, or reads a line
+// from stdin when it is not a terminal.
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		pass, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(pass), err
+	}
+
+	line, err := stdinLines.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
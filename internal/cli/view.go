@@ -3,95 +3,107 @@ package cli
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strconv"
 
 	"github.com/spf13/cobra"
+	"orego/internal/actions"
+	"orego/internal/config"
 	"orego/internal/db"
+	"orego/internal/imaging"
+	"orego/internal/vault"
 )
 
 var (
-	useIcat bool
+	useIcat      bool
+	viewQuery    string
+	viewRevision int
 )
 
 var viewCmd = &cobra.Command{
-	Use:   "view [id]",
-	Short: "Open a screenshot in the default viewer",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
-		}
-		return nil
-	},
-	Run: runView,
+	Use:   "view [id|range|list]...",
+	Short: "Open screenshots in the default viewer",
+	Args:  selectorArgs,
+	Run:   runView,
 }
 
 func init() {
-	viewCmd.Flags().BoolVarP(&useIcat, "icat", "i", true, "Render image in terminal using kitty icat")
+	viewCmd.Flags().BoolVarP(&useIcat, "icat", "i", true, "Render images in the terminal with the terminal_image command")
+	viewCmd.Flags().IntVarP(&viewRevision, "revision", "r", 0, "Open an earlier revision of an edited screenshot")
+	addSelectorFlags(viewCmd, &viewQuery)
 	rootCmd.AddCommand(viewCmd)
 }
 
 func runView(cmd *cobra.Command, args []string) {
-	homeDir, err := os.UserHomeDir()
+	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home dir: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	dbPath := filepath.Join(homeDir, ".local/share/orego/orego.db")
-	store, err := db.New(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
 		os.Exit(1)
 	}
 	defer store.Close()
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid ID: %v\n", err)
-		os.Exit(1)
-	}
-
-	path, err := store.GetScreenshotPath(id)
+	ids, err := resolveSelection(store, args, viewQuery)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "File no longer exists: %s\n", path)
-		fmt.Println("Tip: Run 'orego cleanup' to remove stale records.")
-		os.Exit(1)
-	}
-
-	if useIcat {
-		fmt.Printf("Rendering %s with icat...\n", path)
-
-		// Open the file to pipe it into stdin
-		file, err := os.Open(path)
+	if cmd.Flags().Changed("revision") {
+		if len(ids) != 1 {
+			fmt.Fprintln(os.Stderr, "Error: --revision needs exactly one screenshot")
+			os.Exit(1)
+		}
+		path, err := revisionFilePath(store, ids[0], viewRevision)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		defer file.Close()
-
-		// Use stream transfer mode and pipe content via stdin
-		icatCmd := exec.Command("kitty", "+kitten", "icat", "--transfer-mode=stream")
-		icatCmd.Stdin = file
-		icatCmd.Stdout = os.Stdout
-		icatCmd.Stderr = os.Stderr
-
-		if err := icatCmd.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error running icat: %v\n", err)
+		if err := viewPath(cfg, path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
-	fmt.Printf("Opening %s...\n", path)
-	if err := exec.Command("xdg-open", path).Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening viewer: %v\n", err)
+	paths, pathsErr := existingPaths(store, ids)
+	for _, path := range paths {
+		if err := viewPath(cfg, path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if pathsErr != nil {
 		os.Exit(1)
 	}
 }
+
+func revisionFilePath(store *db.Store, id int64, revision int) (string, error) {
+	revisions, err := store.ListRevisions(id)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range revisions {
+		if r.Number == revision {
+			return r.FilePath, nil
+		}
+	}
+	if revision == 0 && len(revisions) == 0 {
+		return store.GetScreenshotPath(id)
+	}
+	return "", fmt.Errorf("screenshot %d has no revision %d", id, revision)
+}
+
+func viewPath(cfg config.Config, path string) error {
+	// Terminals only render stills; recordings go to the viewer.
+	if useIcat && !imaging.IsVideo(vault.PlainName(path)) {
+		fmt.Printf("Rendering %s with %s...\n", path, cfg.TerminalImage.Cmd)
+		return actions.ShowInTerminal(cfg, path)
+	}
+
+	fmt.Printf("Opening %s...\n", path)
+	return actions.OpenFile(cfg, path)
+}
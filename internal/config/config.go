@@ -1,14 +1,5 @@
 package config
 
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
-	"text/template"
-)
-
 type CommandConfig struct {
 	Cmd  string   `json:"cmd"`
 	Args []string `json:"args"`
@@ -26,16 +17,173 @@ type EditorConfig struct {
 	ArgsOCR []string `json:"args_ocr"`
 }
 
+type RedactionRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type RedactionConfig struct {
+	Enabled   bool            `json:"enabled"`
+	OCR       CommandConfig   `json:"ocr"`
+	BlockSize int             `json:"block_size"`
+	Padding   int             `json:"padding"`
+	Rules     []RedactionRule `json:"rules"`
+}
+
+// StageConfig is one step of the post-capture pipeline. Type is one of
+// editor, none, swappy, custom or edit-later; Cmd and Args override the
+// command for editor and swappy and are required for custom.
+type StageConfig struct {
+	Type string   `json:"type"`
+	Cmd  string   `json:"cmd,omitempty"`
+	Args []string `json:"args,omitempty"`
+}
+
 type CaptureConfig struct {
-	Grim      GrimConfig    `json:"grim"`
-	Editor    EditorConfig  `json:"editor"`
-	OCR       CommandConfig `json:"ocr"`
-	Clipboard CommandConfig `json:"clipboard"`
-	Notify    CommandConfig `json:"notify"`
+	Grim      GrimConfig          `json:"grim"`
+	Editor    EditorConfig        `json:"editor"`
+	OCR       CommandConfig       `json:"ocr"`
+	Clipboard CommandConfig       `json:"clipboard"`
+	Notify    CommandConfig       `json:"notify"`
+	Countdown CommandConfig       `json:"countdown"`
+	Actions   NotifyActionsConfig `json:"actions"`
+	Redaction RedactionConfig     `json:"redaction"`
+	Pipeline  []StageConfig       `json:"pipeline"`
+}
+
+// NotifyActionsConfig configures the notification shown after a capture.
+// Each entry in Actions (open, copy, copy-path, delete) becomes a button;
+// the command must print the key of the chosen action, as
+// `notify-send --action` does.
+type NotifyActionsConfig struct {
+	Enabled bool     `json:"enabled"`
+	Cmd     string   `json:"cmd"`
+	Args    []string `json:"args"`
+	Actions []string `json:"actions"`
+}
+
+// StorageConfig controls how saved screenshots are encoded. png and jpeg
+// are encoded in Go unless Encoders has an entry for them; other formats
+// need an encoder command.
+type StorageConfig struct {
+	Format   string                   `json:"format"`
+	Quality  int                      `json:"quality"`
+	Dir      string                   `json:"dir"`
+	Encoders map[string]CommandConfig `json:"encoders"`
+}
+
+// RecordConfig configures `orego record`. Args is used for a full
+// monitor, ArgsRegion when --region is given.
+type RecordConfig struct {
+	Cmd        string        `json:"cmd"`
+	Args       []string      `json:"args"`
+	ArgsRegion []string      `json:"args_region"`
+	Extension  string        `json:"extension"`
+	Dir        string        `json:"dir"`
+	Poster     CommandConfig `json:"poster"`
+}
+
+// ShareTarget is one upload destination for `orego share`. Which fields
+// apply depends on Type: http, s3, command or local. PublicURL, Headers
+// and Args are templates; see the README for the available fields.
+type ShareTarget struct {
+	Type      string            `json:"type"`
+	URL       string            `json:"url,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Field     string            `json:"field,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	URLPath   string            `json:"url_path,omitempty"`
+	Endpoint  string            `json:"endpoint,omitempty"`
+	Bucket    string            `json:"bucket,omitempty"`
+	Region    string            `json:"region,omitempty"`
+	Prefix    string            `json:"prefix,omitempty"`
+	ACL       string            `json:"acl,omitempty"`
+	AccessKey string            `json:"access_key,omitempty"`
+	SecretKey string            `json:"secret_key,omitempty"`
+	Cmd       string            `json:"cmd,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Dir       string            `json:"dir,omitempty"`
+	PublicURL string            `json:"public_url,omitempty"`
+}
+
+type ShareConfig struct {
+	Default string                 `json:"default"`
+	Targets map[string]ShareTarget `json:"targets"`
+}
+
+// HookConfig is a command run on a lifecycle event. It gets the
+// screenshot as JSON on stdin and OREGO_* environment variables.
+// OnError is "warn" (default), "ignore" or "abort".
+type HookConfig struct {
+	Cmd     string   `json:"cmd"`
+	Args    []string `json:"args"`
+	Timeout string   `json:"timeout"`
+	Async   bool     `json:"async"`
+	OnError string   `json:"on_error"`
+}
+
+type HooksConfig struct {
+	PreCapture []HookConfig `json:"pre_capture"`
+	PostSave   []HookConfig `json:"post_save"`
+	PostDelete []HookConfig `json:"post_delete"`
+}
+
+type RetentionConfig struct {
+	MaxAge       string   `json:"max_age"`
+	MaxPerClass  int      `json:"max_per_class"`
+	MaxTotalSize string   `json:"max_total_size"`
+	KeepTitles   []string `json:"keep_titles"`
+	KeepClasses  []string `json:"keep_classes"`
+	EphemeralTTL string   `json:"ephemeral_ttl"` // Lifetime of --copy-only captures
+}
+
+type PrivacyRule struct {
+	Name   string `json:"name"`
+	Class  string `json:"class"`
+	Title  string `json:"title"`
+	Action string `json:"action"`
+}
+
+type PrivacyConfig struct {
+	BlurRadius int           `json:"blur_radius"`
+	Rules      []PrivacyRule `json:"rules"`
+}
+
+// AppRule adjusts captures of matching windows. Class and Title are
+// regular expressions matched against the active window; the first rule
+// that matches is used. Empty fields keep the global setting.
+// NotifyTitle and NotifyBody are templates that get the default text as
+// {{.Title}} and {{.Body}}.
+type AppRule struct {
+	Name        string       `json:"name"`
+	Class       string       `json:"class,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Editor      EditorConfig `json:"editor"`
+	Format      string       `json:"format,omitempty"`
+	Quality     int          `json:"quality,omitempty"`
+	Dir         string       `json:"dir,omitempty"`
+	OCRArgs     []string     `json:"ocr_args,omitempty"`
+	NotifyTitle string       `json:"notify_title,omitempty"`
+	NotifyBody  string       `json:"notify_body,omitempty"`
 }
 
+// Config is the whole configuration. Viewer, FileManager,
+// ImageClipboard and TerminalImage are used by view, copy, the TUI,
+// notifications and Tarragon; the image is piped to the stdin of
+// ImageClipboard and TerminalImage.
 type Config struct {
-	Capture CaptureConfig `json:"capture"`
+	Capture        CaptureConfig   `json:"capture"`
+	Viewer         CommandConfig   `json:"viewer"`
+	FileManager    CommandConfig   `json:"file_manager"`
+	ImageClipboard CommandConfig   `json:"image_clipboard"`
+	TerminalImage  CommandConfig   `json:"terminal_image"`
+	Storage        StorageConfig   `json:"storage"`
+	Record         RecordConfig    `json:"record"`
+	Share          ShareConfig     `json:"share"`
+	Hooks          HooksConfig     `json:"hooks"`
+	Retention      RetentionConfig `json:"retention"`
+	Privacy        PrivacyConfig   `json:"privacy"`
+	AppRules       []AppRule       `json:"app_rules"`
 }
 
 func Default() Config {
@@ -63,60 +211,85 @@ func Default() Config {
 				Cmd:  "notify-send",
 				Args: []string{"{{.Title}}", "{{.Body}}"},
 			},
+			// The synchronous hint makes notification daemons replace the
+			// previous tick instead of stacking them.
+			Countdown: CommandConfig{
+				Cmd:  "notify-send",
+				Args: []string{"-t", "800", "-h", "string:x-canonical-private-synchronous:orego-countdown", "{{.Title}}", "{{.Body}}"},
+			},
+			// notify-send exits when the notification expires, so an
+			// ignored notification does not keep the capture running.
+			Actions: NotifyActionsConfig{
+				Enabled: true,
+				Cmd:     "notify-send",
+				Args:    []string{"--app-name=OreGo", "--expire-time=15000", "--wait", "{{.Title}}", "{{.Body}}"},
+				Actions: []string{"open", "copy", "copy-path", "delete"},
+			},
+			Pipeline: []StageConfig{
+				{Type: "editor"},
+			},
+			Redaction: RedactionConfig{
+				Enabled: false,
+				OCR: CommandConfig{
+					Cmd:  "tesseract",
+					Args: []string{"{{.Input}}", "stdout", "-l", "eng", "--psm", "11", "tsv"},
+				},
+				BlockSize: 12,
+				Padding:   4,
+				Rules: []RedactionRule{
+					{Name: "email", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+					{Name: "api-key", Pattern: `\b(?:sk|pk|rk)[-_](?:live|test|proj)?[-_]?[A-Za-z0-9]{16,}\b|\bgh[pousr]_[A-Za-z0-9]{30,}\b|\bxox[abprs]-[A-Za-z0-9-]{10,}\b|\bAKIA[0-9A-Z]{16}\b|\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+					{Name: "credit-card", Pattern: `\b(?:\d[ -]?){13,16}\b`},
+				},
+			},
+		},
+		Viewer: CommandConfig{
+			Cmd:  "xdg-open",
+			Args: []string{"{{.Input}}"},
+		},
+		FileManager: CommandConfig{
+			Cmd:  "xdg-open",
+			Args: []string{"{{.Dir}}"},
+		},
+		ImageClipboard: CommandConfig{
+			Cmd:  "wl-copy",
+			Args: []string{"--type", "{{.MimeType}}"},
+		},
+		TerminalImage: CommandConfig{
+			Cmd:  "kitty",
+			Args: []string{"+kitten", "icat", "--transfer-mode=stream"},
+		},
+		Storage: StorageConfig{
+			Format:  "png",
+			Quality: 90,
+			Encoders: map[string]CommandConfig{
+				"webp": {Cmd: "cwebp", Args: []string{"-quiet", "-q", "{{.Quality}}", "{{.Input}}", "-o", "{{.Output}}"}},
+				"avif": {Cmd: "avifenc", Args: []string{"-q", "{{.Quality}}", "{{.Input}}", "{{.Output}}"}},
+			},
+		},
+		Record: RecordConfig{
+			Cmd:        "wf-recorder",
+			Args:       []string{"-o", "{{.Monitor}}", "-f", "{{.Output}}"},
+			ArgsRegion: []string{"-g", "{{.Region}}", "-f", "{{.Output}}"},
+			Extension:  "mp4",
+			Poster: CommandConfig{
+				Cmd:  "ffmpeg",
+				Args: []string{"-y", "-loglevel", "error", "-i", "{{.Input}}", "-frames:v", "1", "{{.Output}}"},
+			},
+		},
+		// The local target copies files into a folder, handy to try the
+		// flow or to share through a synced directory.
+		Share: ShareConfig{
+			Default: "local",
+			Targets: map[string]ShareTarget{
+				"local": {Type: "local", Dir: "~/Pictures/Screenshots/shared"},
+			},
+		},
+		Retention: RetentionConfig{
+			EphemeralTTL: "1h",
+		},
+		Privacy: PrivacyConfig{
+			BlurRadius: 24,
 		},
 	}
 }
-
-func Path() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to find config dir: %w", err)
-	}
-
-	return filepath.Join(configDir, "orego", "config.json"), nil
-}
-
-func Load() (Config, error) {
-	cfg := Default()
-
-	configPath, err := Path()
-	if err != nil {
-		return cfg, err
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return cfg, nil
-		}
-		return cfg, fmt.Errorf("failed to read config: %w", err)
-	}
-
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return cfg, fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	return cfg, nil
-}
-
-func RenderArgs(args []string, data map[string]string) ([]string, error) {
-	rendered := make([]string, 0, len(args))
-	for _, arg := range args {
-		if arg == "" {
-			rendered = append(rendered, arg)
-			continue
-		}
-		tmpl, err := template.New("arg").Option("missingkey=error").Parse(arg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse arg template: %w", err)
-		}
-
-		var buf bytes.Buffer
-		if err := tmpl.Execute(&buf, data); err != nil {
-			return nil, fmt.Errorf("failed to render arg template: %w", err)
-		}
-		rendered = append(rendered, buf.String())
-	}
-
-	return rendered, nil
-}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts the environment variables that override config keys:
+// OREGO_CAPTURE_EDITOR_CMD sets capture.editor.cmd.
+const envPrefix = "OREGO_"
+
+// fromEnv builds a config layer from OREGO_* variables in environ. It
+// returns the layer and the variables that were applied. Variables that
+// name no key, like the ones hooks get, are ignored. Table keys are
+// lowercased; lists take a JSON array or whitespace separated words.
+func fromEnv(environ []string) (map[string]any, []string, []error) {
+	layer := map[string]any{}
+	var applied []string
+	var errs []error
+
+	sort.Strings(environ)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) || name == "OREGO_PROFILE" {
+			continue
+		}
+		keys, t, ok := resolveEnv(strings.ToLower(strings.TrimPrefix(name, envPrefix)), configType)
+		if !ok {
+			continue
+		}
+		v, err := parseEnvValue(value, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", name, strings.Join(keys, "."), err))
+			continue
+		}
+		set(layer, keys, v)
+		applied = append(applied, name)
+	}
+	return layer, applied, errs
+}
+
+// resolveEnv splits an underscore separated name into the key path it
+// names in t. Keys may contain underscores themselves, so every split is
+// tried. Only scalars and lists of scalars can be set.
+func resolveEnv(name string, t reflect.Type) ([]string, reflect.Type, bool) {
+	if name == "" {
+		return nil, t, isEnvSettable(t)
+	}
+
+	var candidates []string
+	var elem func(key string) (reflect.Type, bool)
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			candidates = append(candidates, jsonName(t.Field(i)))
+		}
+		elem = func(key string) (reflect.Type, bool) {
+			f, ok := fieldByKey(t, key)
+			return f.Type, ok
+		}
+	case reflect.Map:
+		for i, c := range name {
+			if c == '_' {
+				candidates = append(candidates, name[:i])
+			}
+		}
+		candidates = append(candidates, name)
+		elem = func(string) (reflect.Type, bool) { return t.Elem(), true }
+	default:
+		return nil, nil, false
+	}
+
+	for _, key := range candidates {
+		var rest string
+		switch {
+		case name == key:
+		case strings.HasPrefix(name, key+"_"):
+			rest = name[len(key)+1:]
+		default:
+			continue
+		}
+		ft, ok := elem(key)
+		if !ok {
+			continue
+		}
+		if keys, lt, ok := resolveEnv(rest, ft); ok {
+			return append([]string{key}, keys...), lt, true
+		}
+	}
+	return nil, nil, false
+}
+
+func isEnvSettable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func parseEnvValue(value string, t reflect.Type) (any, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", value)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", value)
+		}
+		return n, nil
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var list []string
+			if err := json.Unmarshal([]byte(value), &list); err != nil {
+				return nil, fmt.Errorf("invalid list: %w", err)
+			}
+			return toAnySlice(list), nil
+		}
+		return toAnySlice(strings.Fields(value)), nil
+	}
+	return value, nil
+}
+
+func toAnySlice(list []string) []any {
+	out := make([]any, len(list))
+	for i, s := range list {
+		out[i] = s
+	}
+	return out
+}
+
+// set stores v at the key path in m, creating tables on the way.
+func set(m map[string]any, keys []string, v any) {
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := m[k].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = v
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions are the config file formats, in the order they are looked
+// for.
+var Extensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// profile is the profile picked with --profile; OREGO_PROFILE is used
+// when it is empty.
+var profile string
+
+// SetProfile selects the profile overlay that Load applies.
+func SetProfile(name string) {
+	profile = name
+}
+
+// Profile returns the selected profile, or "" for none.
+func Profile() string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv("OREGO_PROFILE")
+}
+
+// Dir is the directory holding config files.
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config dir: %w", err)
+	}
+	return filepath.Join(configDir, "orego"), nil
+}
+
+// Path returns the main config file, or config.json in Dir if there is
+// none yet.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	found, err := mainFile(dir)
+	if err != nil || found != "" {
+		return found, err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// mainFile returns the config.* file in dir, or "" if there is none.
+// Two of them would make the precedence unclear, so that is an error.
+func mainFile(dir string) (string, error) {
+	var found []string
+	for _, ext := range Extensions {
+		p := filepath.Join(dir, "config"+ext)
+		if _, err := os.Stat(p); err == nil {
+			found = append(found, p)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("several config files found (%s); keep one", strings.Join(found, ", "))
+}
+
+// Loaded is a configuration and where it came from.
+type Loaded struct {
+	Config  Config
+	Files   []string // Lowest precedence first
+	Env     []string // OREGO_* variables that were applied
+	Profile string
+}
+
+// Files returns the config files for profile, lowest precedence first:
+// config.{toml,yaml,yml,json}, conf.d/* in name order, then
+// profiles/<profile>.*.
+func Files(profile string) ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	main, err := mainFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	if main != "" {
+		files = append(files, main)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "conf.d"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read conf.d: %w", err)
+	}
+	var dropIns []string
+	for _, e := range entries {
+		if !e.IsDir() && isConfigFile(e.Name()) {
+			dropIns = append(dropIns, filepath.Join(dir, "conf.d", e.Name()))
+		}
+	}
+	sort.Strings(dropIns)
+	files = append(files, dropIns...)
+
+	if profile != "" {
+		var found []string
+		for _, ext := range Extensions {
+			p := filepath.Join(dir, "profiles", profile+ext)
+			if _, err := os.Stat(p); err == nil {
+				found = append(found, p)
+			}
+		}
+		switch len(found) {
+		case 0:
+			return nil, fmt.Errorf("profile %q not found in %s", profile, filepath.Join(dir, "profiles"))
+		case 1:
+			files = append(files, found[0])
+		default:
+			return nil, fmt.Errorf("several files for profile %q (%s); keep one", profile, strings.Join(found, ", "))
+		}
+	}
+	return files, nil
+}
+
+func isConfigFile(name string) bool {
+	for _, ext := range Extensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load returns the effective configuration: the defaults, overlaid by
+// every config file, the profile and OREGO_* environment variables.
+func Load() (Config, error) {
+	loaded, err := LoadAll()
+	return loaded.Config, err
+}
+
+// LoadAll is Load with the sources. Unknown keys, values of the wrong
+// type and templates that do not parse are errors, reported with the
+// file and key path; all of them are returned joined.
+func LoadAll() (Loaded, error) {
+	loaded := Loaded{Config: Default(), Profile: Profile()}
+
+	files, err := Files(loaded.Profile)
+	if err != nil {
+		return loaded, err
+	}
+	loaded.Files = files
+
+	tree, err := toTree(Default())
+	if err != nil {
+		return loaded, err
+	}
+
+	var errs []error
+	for _, file := range files {
+		layer, err := readFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		problems := append(checkTree(layer, configType, ""), checkTemplates(layer, "")...)
+		if len(problems) > 0 {
+			for _, p := range problems {
+				errs = append(errs, fmt.Errorf("%s: %w", file, p))
+			}
+			continue
+		}
+		tree = merge(tree, layer).(map[string]any)
+	}
+
+	envLayer, applied, envErrs := fromEnv(os.Environ())
+	errs = append(errs, envErrs...)
+	errs = append(errs, checkTemplates(envLayer, "")...)
+	tree = merge(tree, envLayer).(map[string]any)
+	loaded.Env = applied
+
+	if len(errs) > 0 {
+		return loaded, errors.Join(errs...)
+	}
+
+	cfg, err := fromTree(tree)
+	if err != nil {
+		return loaded, err
+	}
+	loaded.Config = cfg
+	return loaded, nil
+}
+
+// readFile parses a config file into a generic tree by its extension.
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	tree := map[string]any{}
+	switch filepath.Ext(path) {
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&tree)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse config: %w", path, err)
+	}
+	if tree == nil {
+		tree = map[string]any{} // An empty YAML file
+	}
+	return tree, nil
+}
+
+// merge overlays b on a: objects merge key by key, anything else in b
+// replaces a. Lists are replaced, not appended.
+func merge(a, b any) any {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if !aok || !bok {
+		return b
+	}
+	out := make(map[string]any, len(am)+len(bm))
+	for k, v := range am {
+		out[k] = v
+	}
+	for k, v := range bm {
+		if prev, ok := out[k]; ok {
+			out[k] = merge(prev, v)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// toTree turns a config into the generic form the files are read into.
+func toTree(cfg Config) (map[string]any, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func fromTree(tree map[string]any) (Config, error) {
+	var cfg Config
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to apply config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Encode writes cfg as toml, yaml or json.
+func Encode(cfg Config, format string) ([]byte, error) {
+	tree, err := toTree(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.TrimPrefix(format, ".") {
+	case "toml":
+		return toml.Marshal(normalizeNumbers(tree))
+	case "yaml", "yml":
+		return yaml.Marshal(normalizeNumbers(tree))
+	case "json":
+		return json.MarshalIndent(tree, "", "  ")
+	}
+	return nil, fmt.Errorf("unknown config format %q (supported: toml, yaml, json)", format)
+}
+
+// normalizeNumbers replaces json.Number, which the TOML and YAML encoders
+// would write as strings.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"orego/pkg/models"
+)
+
+// TemplateData is what command templates can refer to. Fields that do
+// not apply to a command are empty.
+type TemplateData struct {
+	Input    string
+	Output   string
+	Dir      string // Folder containing Input, for file managers
+	MimeType string // Type of the image piped to clipboard commands
+	Monitor  string
+	Region   string // Recording region as "X,Y WxH"
+	Title    string // Notification title
+	Body     string // Notification body
+	Quality  int    // Encoder quality
+
+	// Screenshot is the window context of the capture: active window,
+	// workspace and clients. It is the zero value when there is none.
+	Screenshot models.Screenshot
+	Geometry   models.Geometry // Area being captured, in layout coordinates
+	Time       time.Time       // Capture time, or now
+}
+
+// NewTemplateData returns template data for sc, which may be nil.
+func NewTemplateData(sc *models.Screenshot) TemplateData {
+	var data TemplateData
+	if sc == nil {
+		return data
+	}
+	data.Screenshot = *sc
+	data.Monitor = sc.Workspace.Monitor
+	data.Time = sc.Capture.Ts
+	if sc.Region != nil {
+		data.Geometry = *sc.Region
+	}
+	return data
+}
+
+// Class is the class of the active window.
+func (d TemplateData) Class() string {
+	return d.Screenshot.ActiveWindow.Class
+}
+
+// WindowTitle is the title of the active window.
+func (d TemplateData) WindowTitle() string {
+	return d.Screenshot.ActiveWindow.Title
+}
+
+// TemplateFuncs are the functions available in command templates:
+//
+//	{{env "HOME"}}  {{lower .Class}}  {{slug .WindowTitle}}  {{date "2006-01-02" .Time}}
+var TemplateFuncs = template.FuncMap{
+	"env":   os.Getenv,
+	"lower": strings.ToLower,
+	"slug":  Slug,
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// Slug lowercases s and joins its letters and digits with dashes, for
+// use in file names.
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+func RenderArgs(args []string, data TemplateData) ([]string, error) {
+	if data.Time.IsZero() {
+		data.Time = time.Now()
+	}
+
+	rendered := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" {
+			rendered = append(rendered, arg)
+			continue
+		}
+		tmpl, err := template.New("arg").Funcs(TemplateFuncs).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse arg template: %w", err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render arg template: %w", err)
+		}
+		rendered = append(rendered, buf.String())
+	}
+
+	return rendered, nil
+}
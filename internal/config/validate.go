@@ -0,0 +1,171 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var configType = reflect.TypeOf(Config{})
+
+// fieldByKey returns the struct field whose json name is key.
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if jsonName(f) == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys keeps error messages in a stable order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkTree reports keys of v that t does not have and values of the
+// wrong type, by key path. null is accepted anywhere and means the zero
+// value.
+func checkTree(v any, t reflect.Type, path string) []error {
+	if v == nil {
+		return nil
+	}
+	mismatch := func(want string) []error {
+		return []error{fmt.Errorf("%s: expected %s, got %s", path, want, describe(v))}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch("a table")
+		}
+		var errs []error
+		for _, k := range sortedKeys(m) {
+			f, ok := fieldByKey(t, k)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key", joinKey(path, k)))
+				continue
+			}
+			errs = append(errs, checkTree(m[k], f.Type, joinKey(path, k))...)
+		}
+		return errs
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch("a table")
+		}
+		var errs []error
+		for _, k := range sortedKeys(m) {
+			errs = append(errs, checkTree(m[k], t.Elem(), joinKey(path, k))...)
+		}
+		return errs
+	case reflect.Slice:
+		list, ok := v.([]any)
+		if !ok {
+			return mismatch("a list")
+		}
+		var errs []error
+		for i, e := range list {
+			errs = append(errs, checkTree(e, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return mismatch("a string")
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return mismatch("true or false")
+		}
+	case reflect.Int, reflect.Int64:
+		if !isInteger(v) {
+			return mismatch("an integer")
+		}
+	}
+	return nil
+}
+
+// isInteger accepts the integer types the JSON, TOML and YAML decoders
+// produce.
+func isInteger(v any) bool {
+	switch n := v.(type) {
+	case int, int64, uint64:
+		return true
+	case json.Number:
+		_, err := strconv.ParseInt(string(n), 10, 64)
+		return err == nil
+	case float64:
+		return n == math.Trunc(n)
+	}
+	return false
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int, int64, uint64, float64, json.Number:
+		return "a number"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a table"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// checkTemplates parses every string that looks like a template, so a
+// typo is reported when the config is loaded rather than at capture time.
+func checkTemplates(v any, path string) []error {
+	switch v := v.(type) {
+	case map[string]any:
+		var errs []error
+		for _, k := range sortedKeys(v) {
+			errs = append(errs, checkTemplates(v[k], joinKey(path, k))...)
+		}
+		return errs
+	case []any:
+		var errs []error
+		for i, e := range v {
+			errs = append(errs, checkTemplates(e, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case string:
+		if !strings.Contains(v, "{{") {
+			return nil
+		}
+		if _, err := template.New("value").Funcs(TemplateFuncs).Parse(v); err != nil {
+			return []error{fmt.Errorf("%s: invalid template: %w", path, err)}
+		}
+	}
+	return nil
+}
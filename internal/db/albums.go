@@ -0,0 +1,197 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"orego/pkg/models"
+)
+
+// settingCurrentAlbum names the album new captures are added to.
+const settingCurrentAlbum = "current_album"
+
+// ensureAlbum returns the ID of the named album, creating it if needed.
+func ensureAlbum(tx *sql.Tx, name string) (int64, error) {
+	if _, err := tx.Exec("INSERT OR IGNORE INTO albums (name, description, created_at) VALUES (?, '', ?)", name, time.Now()); err != nil {
+		return 0, fmt.Errorf("failed to create album %q: %w", name, err)
+	}
+	var id int64
+	if err := tx.QueryRow("SELECT id FROM albums WHERE name = ?", name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to query album %q: %w", name, err)
+	}
+	return id, nil
+}
+
+func (s *Store) albumID(name string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow("SELECT id FROM albums WHERE name = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("album %q not found", name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query album: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Store) CreateAlbum(name, description string) error {
+	_, err := s.db.Exec("INSERT INTO albums (name, description, created_at) VALUES (?, ?, ?)", name, description, time.Now())
+	if err != nil && strings.Contains(err.Error(), "UNIQUE") {
+		return fmt.Errorf("album %q already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create album: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlbum removes an album. Its screenshots are kept.
+func (s *Store) DeleteAlbum(name string) error {
+	id, err := s.albumID(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM album_screenshots WHERE album_id = ?", id); err != nil {
+		return fmt.Errorf("failed to empty album: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM albums WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete album: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM settings WHERE key = ? AND value = ?", settingCurrentAlbum, name); err != nil {
+		return fmt.Errorf("failed to clear current album: %w", err)
+	}
+	return tx.Commit()
+}
+
+// AddToAlbum adds screenshots to an existing album and returns how many
+// were not already in it.
+func (s *Store) AddToAlbum(name string, ids []int64) (int, error) {
+	albumID, err := s.albumID(name)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	added := 0
+	now := time.Now()
+	for _, id := range ids {
+		res, err := tx.Exec("INSERT OR IGNORE INTO album_screenshots (album_id, screenshot_id, added_at) VALUES (?, ?, ?)", albumID, id, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add %d to album: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+	return added, tx.Commit()
+}
+
+// RemoveFromAlbum takes screenshots out of an album and returns how many
+// were in it.
+func (s *Store) RemoveFromAlbum(name string, ids []int64) (int, error) {
+	albumID, err := s.albumID(name)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	removed := 0
+	for _, id := range ids {
+		res, err := tx.Exec("DELETE FROM album_screenshots WHERE album_id = ? AND screenshot_id = ?", albumID, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove %d from album: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			removed++
+		}
+	}
+	return removed, tx.Commit()
+}
+
+// GetAlbum returns an album with its screenshot count.
+func (s *Store) GetAlbum(name string) (*models.Album, error) {
+	albums, err := s.queryAlbums("WHERE a.name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(albums) == 0 {
+		return nil, fmt.Errorf("album %q not found", name)
+	}
+	return &albums[0], nil
+}
+
+// ListAlbums returns all albums with their screenshot counts, by name.
+func (s *Store) ListAlbums() ([]models.Album, error) {
+	return s.queryAlbums("")
+}
+
+func (s *Store) queryAlbums(where string, args ...interface{}) ([]models.Album, error) {
+	rows, err := s.db.Query(`
+		SELECT a.id, a.name, COALESCE(a.description, ''), a.created_at, COUNT(m.screenshot_id)
+		FROM albums a
+		LEFT JOIN album_screenshots m ON m.album_id = a.id
+		`+where+`
+		GROUP BY a.id
+		ORDER BY a.name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query albums: %w", err)
+	}
+	defer rows.Close()
+
+	var albums []models.Album
+	for rows.Next() {
+		var a models.Album
+		var createdAt time.Time
+		if err := rows.Scan(&a.ID, &a.Name, &a.Description, &createdAt, &a.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan album: %w", err)
+		}
+		a.CreatedAt = createdAt
+		albums = append(albums, a)
+	}
+	return albums, nil
+}
+
+// CurrentAlbum returns the album new captures go to, or "" if none is set.
+func (s *Store) CurrentAlbum() (string, error) {
+	var name string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key = ?", settingCurrentAlbum).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query current album: %w", err)
+	}
+	return name, nil
+}
+
+// SetCurrentAlbum makes name the current album; an empty name clears it.
+func (s *Store) SetCurrentAlbum(name string) error {
+	if name == "" {
+		_, err := s.db.Exec("DELETE FROM settings WHERE key = ?", settingCurrentAlbum)
+		return err
+	}
+	if _, err := s.albumID(name); err != nil {
+		return err
+	}
+	_, err := s.db.Exec("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", settingCurrentAlbum, name)
+	return err
+}
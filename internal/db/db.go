@@ -2,9 +2,11 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -12,7 +14,16 @@ import (
 )
 
 type Store struct {
-	db *sql.DB
+	db       *sql.DB
+	cipher   FieldCipher
+	onDelete func([]models.Screenshot)
+}
+
+// FieldCipher encrypts sensitive columns (window titles and client lists).
+// Open must pass through values that were stored unencrypted.
+type FieldCipher interface {
+	Seal(string) (string, error)
+	Open(string) (string, error)
 }
 
 func New(dbPath string) (*Store, error) {
@@ -38,6 +49,36 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
+// SetCipher enables transparent encryption of sensitive columns.
+func (s *Store) SetCipher(c FieldCipher) {
+	s.cipher = c
+}
+
+// OnDelete registers a callback run after screenshots were deleted and
+// their files removed. The delete cannot be undone at that point, so the
+// callback handles its own errors.
+func (s *Store) OnDelete(fn func([]models.Screenshot)) {
+	s.onDelete = fn
+}
+
+func (s *Store) seal(v string) (string, error) {
+	if s.cipher == nil {
+		return v, nil
+	}
+	return s.cipher.Seal(v)
+}
+
+func (s *Store) open(v string) string {
+	if s.cipher == nil {
+		return v
+	}
+	plain, err := s.cipher.Open(v)
+	if err != nil {
+		return v
+	}
+	return plain
+}
+
 func (s *Store) init() error {
 	queryScreenshots := `
 	CREATE TABLE IF NOT EXISTS screenshots (
@@ -84,16 +125,198 @@ func (s *Store) init() error {
 		FOREIGN KEY(screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
 	);`
 
+	// Table for redaction rules that fired during capture (one-to-many)
+	queryRedactions := `
+	CREATE TABLE IF NOT EXISTS redactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		screenshot_id INTEGER,
+		rule TEXT,
+		count INTEGER,
+		FOREIGN KEY(screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
+	);`
+
+	// Table for edited versions of a screenshot (one-to-many)
+	queryRevisions := `
+	CREATE TABLE IF NOT EXISTS screenshot_revisions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		screenshot_id INTEGER,
+		revision INTEGER,
+		file_path TEXT NOT NULL,
+		created_at DATETIME,
+		UNIQUE(screenshot_id, revision),
+		FOREIGN KEY(screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
+	);`
+
+	// Table for named groups of captures, e.g. time-lapse sequences
+	querySessions := `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		created_at DATETIME
+	);`
+
+	// Albums are user-curated groups; a screenshot can be in several
+	queryAlbums := `
+	CREATE TABLE IF NOT EXISTS albums (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT,
+		created_at DATETIME
+	);`
+
+	queryAlbumScreenshots := `
+	CREATE TABLE IF NOT EXISTS album_screenshots (
+		album_id INTEGER,
+		screenshot_id INTEGER,
+		added_at DATETIME,
+		PRIMARY KEY(album_id, screenshot_id),
+		FOREIGN KEY(album_id) REFERENCES albums(id) ON DELETE CASCADE,
+		FOREIGN KEY(screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
+	);`
+
+	// Links produced by `orego share`
+	queryShares := `
+	CREATE TABLE IF NOT EXISTS shares (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		screenshot_id INTEGER,
+		target TEXT NOT NULL,
+		url TEXT NOT NULL,
+		created_at DATETIME,
+		FOREIGN KEY(screenshot_id) REFERENCES screenshots(id) ON DELETE CASCADE
+	);`
+
+	// Key/value store for persistent CLI state such as the current album
+	querySettings := `
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT
+	);`
+
 	if _, err := s.db.Exec(queryScreenshots); err != nil {
 		return fmt.Errorf("failed to create screenshots table: %w", err)
 	}
 	if _, err := s.db.Exec(queryClients); err != nil {
 		return fmt.Errorf("failed to create clients table: %w", err)
 	}
+	if _, err := s.db.Exec(queryRedactions); err != nil {
+		return fmt.Errorf("failed to create redactions table: %w", err)
+	}
+	if _, err := s.db.Exec(queryRevisions); err != nil {
+		return fmt.Errorf("failed to create screenshot_revisions table: %w", err)
+	}
+	if _, err := s.db.Exec(querySessions); err != nil {
+		return fmt.Errorf("failed to create sessions table: %w", err)
+	}
+	if _, err := s.db.Exec(queryAlbums); err != nil {
+		return fmt.Errorf("failed to create albums table: %w", err)
+	}
+	if _, err := s.db.Exec(queryAlbumScreenshots); err != nil {
+		return fmt.Errorf("failed to create album_screenshots table: %w", err)
+	}
+	if _, err := s.db.Exec(queryShares); err != nil {
+		return fmt.Errorf("failed to create shares table: %w", err)
+	}
+	if _, err := s.db.Exec(querySettings); err != nil {
+		return fmt.Errorf("failed to create settings table: %w", err)
+	}
+	columns := []struct{ name, decl string }{
+		{"session_id", "INTEGER REFERENCES sessions(id)"},
+		{"media_type", "TEXT NOT NULL DEFAULT 'image'"},
+		{"duration", "REAL"},
+		{"size", "INTEGER"},
+		{"poster_path", "TEXT"},
+		{"end_window_class", "TEXT"},
+		{"end_window_title", "TEXT"},
+		{"mime_type", "TEXT"},
+		{"width", "INTEGER"},
+		{"height", "INTEGER"},
+		{"origin", "TEXT NOT NULL DEFAULT 'capture'"},
+		{"expires_at", "DATETIME"},
+		{"app_rule", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := s.addColumn("screenshots", c.name, c.decl); err != nil {
+			return err
+		}
+	}
+	// Revisions keep their image details so a revert can restore them.
+	revisionColumns := []struct{ name, decl string }{
+		{"mime_type", "TEXT"},
+		{"width", "INTEGER"},
+		{"height", "INTEGER"},
+		{"size", "INTEGER"},
+	}
+	for _, c := range revisionColumns {
+		if err := s.addColumn("screenshot_revisions", c.name, c.decl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn adds a column to an existing table unless it is already
+// there, so databases created by older versions keep working.
+func (s *Store) addColumn(table, column, decl string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    bool
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
 func (s *Store) Save(sc *models.Screenshot) error {
+	title, err := s.seal(sc.ActiveWindow.Title)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt title: %w", err)
+	}
+	lastTitle, err := s.seal(sc.Workspace.LastWindowTitle)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt title: %w", err)
+	}
+	var endClass, endTitle sql.NullString
+	if sc.EndWindow != nil {
+		endClass = sql.NullString{String: sc.EndWindow.Class, Valid: true}
+		sealed, err := s.seal(sc.EndWindow.Title)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt title: %w", err)
+		}
+		endTitle = sql.NullString{String: sealed, Valid: true}
+	}
+	mediaType := sc.MediaType
+	if mediaType == "" {
+		mediaType = models.MediaImage
+	}
+	origin := sc.Origin
+	if origin == "" {
+		origin = models.OriginCapture
+	}
+	var expiresAt sql.NullTime
+	if sc.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *sc.ExpiresAt, Valid: true}
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
@@ -105,12 +328,18 @@ func (s *Store) Save(sc *models.Screenshot) error {
 			file_path, capture_ts, capture_timezone, capture_hostname, capture_user, capture_command, capture_version,
 			active_window_address, active_window_class, active_window_title, active_window_pid,
 			active_window_floating, active_window_fullscreen, active_window_xwayland, active_window_pinned,
-			workspace_id, workspace_name, workspace_monitor, workspace_windows, workspace_has_fullscreen, workspace_last_window_title
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			workspace_id, workspace_name, workspace_monitor, workspace_windows, workspace_has_fullscreen, workspace_last_window_title,
+			session_id, media_type, duration, size, poster_path, end_window_class, end_window_title,
+			mime_type, width, height, origin, expires_at, app_rule
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		sc.FilePath, sc.Capture.Ts, sc.Capture.Timezone, sc.Capture.Hostname, sc.Capture.User, sc.Capture.Command, sc.Capture.Version,
-		sc.ActiveWindow.Address, sc.ActiveWindow.Class, sc.ActiveWindow.Title, sc.ActiveWindow.Pid,
+		sc.ActiveWindow.Address, sc.ActiveWindow.Class, title, sc.ActiveWindow.Pid,
 		sc.ActiveWindow.State.Floating, sc.ActiveWindow.State.Fullscreen, sc.ActiveWindow.State.Xwayland, sc.ActiveWindow.State.Pinned,
-		sc.Workspace.ID, sc.Workspace.Name, sc.Workspace.Monitor, sc.Workspace.Windows, sc.Workspace.HasFullscreen, sc.Workspace.LastWindowTitle,
+		sc.Workspace.ID, sc.Workspace.Name, sc.Workspace.Monitor, sc.Workspace.Windows, sc.Workspace.HasFullscreen, lastTitle,
+		sql.NullInt64{Int64: sc.SessionID, Valid: sc.SessionID != 0},
+		mediaType, sc.Duration, sc.Size, sc.PosterPath, endClass, endTitle,
+		sc.MimeType, sc.Width, sc.Height, origin, expiresAt,
+		sql.NullString{String: sc.AppRule, Valid: sc.AppRule != ""},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert screenshot: %w", err)
@@ -123,43 +352,114 @@ func (s *Store) Save(sc *models.Screenshot) error {
 	sc.ID = id
 
 	for _, client := range sc.Clients {
-		_, err := tx.Exec(`
+		class, err := s.seal(client.Class)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt client: %w", err)
+		}
+		clientTitle, err := s.seal(client.Title)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt client: %w", err)
+		}
+		_, err = tx.Exec(`
 			INSERT INTO clients (screenshot_id, address, class, title, pid, workspace_id)
 			VALUES (?, ?, ?, ?, ?, ?)`,
-			id, client.Address, client.Class, client.Title, client.Pid, client.WorkspaceID,
+			id, client.Address, class, clientTitle, client.Pid, client.WorkspaceID,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert client: %w", err)
 		}
 	}
 
+	for _, r := range sc.Redactions {
+		_, err := tx.Exec(`
+			INSERT INTO redactions (screenshot_id, rule, count)
+			VALUES (?, ?, ?)`,
+			id, r.Rule, r.Count,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert redaction: %w", err)
+		}
+	}
+
+	for _, name := range sc.Albums {
+		albumID, err := ensureAlbum(tx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO album_screenshots (album_id, screenshot_id, added_at) VALUES (?, ?, ?)", albumID, id, time.Now()); err != nil {
+			return fmt.Errorf("failed to add screenshot to album %q: %w", name, err)
+		}
+	}
+
 	return tx.Commit()
 }
 
 func (s *Store) ListScreenshots(limit int, filterField, filterValue string) ([]models.Screenshot, error) {
+	return s.FindScreenshots(ListOptions{Limit: limit, Field: filterField, Value: filterValue})
+}
+
+// ListOptions narrows FindScreenshots. Field is one of app, title,
+// session or album and is matched as a substring (album exactly). Album
+// additionally restricts results to one album.
+type ListOptions struct {
+	Limit int
+	Field string
+	Value string
+	Album string
+
+	// WithClients also loads the background clients of each screenshot.
+	WithClients bool
+}
+
+func (s *Store) FindScreenshots(opts ListOptions) ([]models.Screenshot, error) {
 	baseQuery := `
 	SELECT 
 		id, file_path, 
 		capture_ts, capture_timezone, capture_hostname, capture_user, capture_command, capture_version,
 		active_window_address, active_window_class, active_window_title, active_window_pid,
-		workspace_id, workspace_name, workspace_monitor
+		workspace_id, workspace_name, workspace_monitor,
+		COALESCE(media_type, 'image'), COALESCE(duration, 0), COALESCE(size, 0), COALESCE(poster_path, ''),
+		COALESCE(mime_type, ''), COALESCE(width, 0), COALESCE(height, 0),
+		COALESCE(origin, 'capture'), expires_at
 	FROM screenshots`
 
+	var conditions []string
 	var args []interface{}
 
 	// Whitelist filter fields to prevent injection
 	fieldMap := map[string]string{
-		"app":   "active_window_class",
-		"title": "active_window_title",
+		"app":     "active_window_class LIKE ?",
+		"title":   "active_window_title LIKE ?",
+		"session": "session_id IN (SELECT id FROM sessions WHERE name LIKE ?)",
+		"origin":  "origin LIKE ?",
+	}
+	const albumCondition = "id IN (SELECT m.screenshot_id FROM album_screenshots m JOIN albums a ON a.id = m.album_id WHERE a.name = ?)"
+
+	filterField, filterValue := opts.Field, opts.Value
+	if filterField == "album" && filterValue != "" {
+		conditions = append(conditions, albumCondition)
+		args = append(args, filterValue)
+		filterField, filterValue = "", ""
 	}
+	if opts.Album != "" {
+		conditions = append(conditions, albumCondition)
+		args = append(args, opts.Album)
+	}
+
+	// Encrypted titles can't be matched in SQL, filter them after decryption.
+	filterInGo := s.cipher != nil && filterField == "title" && filterValue != ""
 
-	if dbField, ok := fieldMap[filterField]; ok && filterValue != "" {
-		baseQuery += fmt.Sprintf(" WHERE %s LIKE ?", dbField)
+	if condition, ok := fieldMap[filterField]; ok && filterValue != "" && !filterInGo {
+		conditions = append(conditions, condition)
 		args = append(args, "%"+filterValue+"%")
 	}
+	if len(conditions) > 0 {
+		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
 
+	limit := opts.Limit
 	baseQuery += " ORDER BY id DESC"
-	if limit > 0 {
+	if limit > 0 && !filterInGo {
 		baseQuery += " LIMIT ?"
 		args = append(args, limit)
 	}
@@ -174,22 +474,82 @@ func (s *Store) ListScreenshots(limit int, filterField, filterValue string) ([]m
 	for rows.Next() {
 		var sc models.Screenshot
 		var ts time.Time
+		var expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&sc.ID, &sc.FilePath,
 			&ts, &sc.Capture.Timezone, &sc.Capture.Hostname, &sc.Capture.User, &sc.Capture.Command, &sc.Capture.Version,
 			&sc.ActiveWindow.Address, &sc.ActiveWindow.Class, &sc.ActiveWindow.Title, &sc.ActiveWindow.Pid,
 			&sc.Workspace.ID, &sc.Workspace.Name, &sc.Workspace.Monitor,
+			&sc.MediaType, &sc.Duration, &sc.Size, &sc.PosterPath,
+			&sc.MimeType, &sc.Width, &sc.Height,
+			&sc.Origin, &expiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan screenshot: %w", err)
 		}
 		sc.Capture.Ts = ts
+		if expiresAt.Valid {
+			sc.ExpiresAt = &expiresAt.Time
+		}
+		sc.ActiveWindow.Title = s.open(sc.ActiveWindow.Title)
+		if filterInGo && !strings.Contains(strings.ToLower(sc.ActiveWindow.Title), strings.ToLower(filterValue)) {
+			continue
+		}
 		results = append(results, sc)
+		if filterInGo && limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read screenshots: %w", err)
+	}
+	rows.Close()
+
+	if opts.WithClients {
+		if err := s.loadClients(results); err != nil {
+			return nil, err
+		}
 	}
 	return results, nil
 }
 
+// loadClients fills in the clients of each screenshot in place.
+func (s *Store) loadClients(screenshots []models.Screenshot) error {
+	const chunkSize = 500
+
+	byID := make(map[int64]*models.Screenshot, len(screenshots))
+	for i := range screenshots {
+		byID[screenshots[i].ID] = &screenshots[i]
+	}
+	for start := 0; start < len(screenshots); start += chunkSize {
+		chunk := screenshots[start:min(start+chunkSize, len(screenshots))]
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		args := make([]interface{}, len(chunk))
+		for i, sc := range chunk {
+			args[i] = sc.ID
+		}
+
+		rows, err := s.db.Query("SELECT screenshot_id, address, class, title, pid, workspace_id FROM clients WHERE screenshot_id IN ("+placeholders+") ORDER BY id", args...)
+		if err != nil {
+			return fmt.Errorf("failed to query clients: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var c models.Client
+			if err := rows.Scan(&id, &c.Address, &c.Class, &c.Title, &c.Pid, &c.WorkspaceID); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan client: %w", err)
+			}
+			c.Class = s.open(c.Class)
+			c.Title = s.open(c.Title)
+			byID[id].Clients = append(byID[id].Clients, c)
+		}
+		rows.Close()
+	}
+	return nil
+}
+
 func (s *Store) ListAllPaths() (map[int64]string, error) {
 	rows, err := s.db.Query("SELECT id, file_path FROM screenshots")
 	if err != nil {
@@ -209,18 +569,391 @@ func (s *Store) ListAllPaths() (map[int64]string, error) {
 	return paths, nil
 }
 
+// SetFilePath points a screenshot record at a different file.
+func (s *Store) SetFilePath(id int64, path string) error {
+	_, err := s.db.Exec("UPDATE screenshots SET file_path = ? WHERE id = ?", path, id)
+	return err
+}
+
+// ListFilePaths returns every file referenced by a screenshot, one of its
+// revisions or a recording's poster frame.
+func (s *Store) ListFilePaths() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT file_path FROM screenshots
+		UNION
+		SELECT poster_path FROM screenshots WHERE poster_path IS NOT NULL AND poster_path != ''
+		UNION
+		SELECT file_path FROM screenshot_revisions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query paths: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ReplaceFilePath updates every reference to oldPath, e.g. after the file
+// was moved or encrypted.
+func (s *Store) ReplaceFilePath(oldPath, newPath string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE screenshots SET file_path = ? WHERE file_path = ?", newPath, oldPath); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE screenshots SET poster_path = ? WHERE poster_path = ?", newPath, oldPath); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE screenshot_revisions SET file_path = ? WHERE file_path = ?", newPath, oldPath); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SealExisting encrypts sensitive columns of rows stored before the
+// cipher was enabled. Values that are already sealed are left alone.
+func (s *Store) SealExisting() error {
+	if s.cipher == nil {
+		return fmt.Errorf("no cipher configured")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sealColumns := func(table string, columns ...string) error {
+		rows, err := tx.Query(fmt.Sprintf("SELECT id, %s FROM %s", strings.Join(columns, ", "), table))
+		if err != nil {
+			return err
+		}
+		type row struct {
+			id     int64
+			values []sql.NullString
+		}
+		var all []row
+		for rows.Next() {
+			r := row{values: make([]sql.NullString, len(columns))}
+			dest := []interface{}{&r.id}
+			for i := range r.values {
+				dest = append(dest, &r.values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+			all = append(all, r)
+		}
+		rows.Close()
+
+		for _, r := range all {
+			for i, col := range columns {
+				sealed, err := s.cipher.Seal(r.values[i].String)
+				if err != nil {
+					return err
+				}
+				if sealed == r.values[i].String {
+					continue
+				}
+				if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", table, col), sealed, r.id); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := sealColumns("screenshots", "active_window_title", "workspace_last_window_title", "end_window_title"); err != nil {
+		return fmt.Errorf("failed to encrypt screenshots: %w", err)
+	}
+	if err := sealColumns("clients", "class", "title"); err != nil {
+		return fmt.Errorf("failed to encrypt clients: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ExpiredIDs returns ephemeral entries whose expiry has passed.
+func (s *Store) ExpiredIDs(now time.Time) ([]int64, error) {
+	rows, err := s.db.Query("SELECT id, expires_at FROM screenshots WHERE expires_at IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ephemeral screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	// Compared here, stored timestamps do not sort reliably as text.
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var expiresAt time.Time
+		if err := rows.Scan(&id, &expiresAt); err != nil {
+			return nil, err
+		}
+		if now.After(expiresAt) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// ExistingIDs reports which of the given IDs have a screenshot record.
+func (s *Store) ExistingIDs(ids []int64) (map[int64]bool, error) {
+	const chunkSize = 500
+
+	existing := make(map[int64]bool, len(ids))
+	for start := 0; start < len(ids); start += chunkSize {
+		chunk := ids[start:min(start+chunkSize, len(ids))]
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+
+		rows, err := s.db.Query("SELECT id FROM screenshots WHERE id IN ("+placeholders+")", args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ids: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existing[id] = true
+		}
+		rows.Close()
+	}
+	return existing, nil
+}
+
 func (s *Store) DeleteScreenshot(id int64) error {
-	path, err := s.GetScreenshotPath(id)
+	return s.DeleteScreenshots([]int64{id})
+}
+
+// DeleteScreenshots removes the given screenshots and their clients in a
+// single transaction and then deletes their files. If any ID is unknown,
+// nothing is deleted.
+func (s *Store) DeleteScreenshots(ids []int64) error {
+	var deleted []models.Screenshot
+	if s.onDelete != nil {
+		for _, id := range ids {
+			if sc, err := s.GetScreenshot(id); err == nil {
+				deleted = append(deleted, *sc)
+			}
+		}
+	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	paths := make([]string, 0, len(ids))
+	for _, id := range ids {
+		var path, poster string
+		err := tx.QueryRow("SELECT file_path, COALESCE(poster_path, '') FROM screenshots WHERE id = ?", id).Scan(&path, &poster)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("screenshot with ID %d not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query screenshot %d: %w", id, err)
+		}
+		paths = append(paths, path)
+		if poster != "" {
+			paths = append(paths, poster)
+		}
+
+		revisionPaths, err := queryStrings(tx, "SELECT file_path FROM screenshot_revisions WHERE screenshot_id = ?", id)
+		if err != nil {
+			return fmt.Errorf("failed to query revisions of %d: %w", id, err)
+		}
+		for _, p := range revisionPaths {
+			if p != path {
+				paths = append(paths, p)
+			}
+		}
+
+		if _, err := tx.Exec("DELETE FROM screenshot_revisions WHERE screenshot_id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete revisions of %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM clients WHERE screenshot_id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete clients of %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM redactions WHERE screenshot_id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete redactions of %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM album_screenshots WHERE screenshot_id = ?", id); err != nil {
+			return fmt.Errorf("failed to remove %d from albums: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM shares WHERE screenshot_id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete shares of %d: %w", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM screenshots WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete screenshot %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete file %s: %w", path, err)
+	var errs []error
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to delete file %s: %w", path, err))
+		}
+	}
+	if s.onDelete != nil && len(deleted) > 0 {
+		s.onDelete(deleted)
 	}
+	return errors.Join(errs...)
+}
 
-	_, err = s.db.Exec("DELETE FROM screenshots WHERE id = ?", id)
-	return err
+func queryStrings(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// ListRevisions returns the saved versions of a screenshot, oldest first.
+// Screenshots that were never edited have no revisions.
+func (s *Store) ListRevisions(id int64) ([]models.Revision, error) {
+	rows, err := s.db.Query(`
+		SELECT r.revision, r.file_path, r.created_at, r.file_path = sc.file_path,
+			COALESCE(r.mime_type, ''), COALESCE(r.width, 0), COALESCE(r.height, 0), COALESCE(r.size, 0)
+		FROM screenshot_revisions r
+		JOIN screenshots sc ON sc.id = r.screenshot_id
+		WHERE r.screenshot_id = ?
+		ORDER BY r.revision`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []models.Revision
+	for rows.Next() {
+		var r models.Revision
+		var createdAt time.Time
+		if err := rows.Scan(&r.Number, &r.FilePath, &createdAt, &r.Current, &r.MimeType, &r.Width, &r.Height, &r.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		r.CreatedAt = createdAt
+		revisions = append(revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// AddRevision records rev as a new version of the screenshot and makes it
+// current, along with its MIME type, dimensions and size. On the first
+// edit the original file becomes revision 0. rev.Number is ignored.
+func (s *Store) AddRevision(id int64, rev models.Revision) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The details of the original are copied as they are, NULL for
+	// screenshots saved before they were recorded.
+	var currentPath string
+	var capturedAt time.Time
+	var mimeType sql.NullString
+	var width, height, size sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT file_path, capture_ts, mime_type, width, height, size
+		FROM screenshots WHERE id = ?`, id).Scan(&currentPath, &capturedAt, &mimeType, &width, &height, &size)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("screenshot with ID %d not found", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query screenshot: %w", err)
+	}
+
+	var latest sql.NullInt64
+	if err := tx.QueryRow("SELECT MAX(revision) FROM screenshot_revisions WHERE screenshot_id = ?", id).Scan(&latest); err != nil {
+		return 0, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	if !latest.Valid {
+		if _, err := tx.Exec(`
+			INSERT INTO screenshot_revisions (screenshot_id, revision, file_path, created_at, mime_type, width, height, size)
+			VALUES (?, 0, ?, ?, ?, ?, ?, ?)`,
+			id, currentPath, capturedAt, mimeType, width, height, size); err != nil {
+			return 0, fmt.Errorf("failed to record original revision: %w", err)
+		}
+	}
+
+	next := int(latest.Int64) + 1
+	if _, err := tx.Exec(`
+		INSERT INTO screenshot_revisions (screenshot_id, revision, file_path, created_at, mime_type, width, height, size)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, next, rev.FilePath, time.Now(), rev.MimeType, rev.Width, rev.Height, rev.Size); err != nil {
+		return 0, fmt.Errorf("failed to record revision: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE screenshots SET file_path = ?, mime_type = ?, width = ?, height = ?, size = ?
+		WHERE id = ?`, rev.FilePath, rev.MimeType, rev.Width, rev.Height, rev.Size, id); err != nil {
+		return 0, fmt.Errorf("failed to update screenshot: %w", err)
+	}
+
+	return next, tx.Commit()
+}
+
+// SetCurrentRevision makes an existing revision the screenshot's file.
+// Image details the revision does not know are left as they are.
+func (s *Store) SetCurrentRevision(id int64, revision int) (string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var path string
+	var mimeType sql.NullString
+	var width, height, size sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT file_path, mime_type, width, height, size
+		FROM screenshot_revisions WHERE screenshot_id = ? AND revision = ?`,
+		id, revision).Scan(&path, &mimeType, &width, &height, &size)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("screenshot %d has no revision %d", id, revision)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query revision: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE screenshots SET file_path = ?, mime_type = COALESCE(?, mime_type),
+			width = COALESCE(?, width), height = COALESCE(?, height), size = COALESCE(?, size)
+		WHERE id = ?`, path, mimeType, width, height, size, id); err != nil {
+		return "", fmt.Errorf("failed to update screenshot: %w", err)
+	}
+	return path, tx.Commit()
 }
 
 func (s *Store) GetScreenshotPath(id int64) (string, error) {
@@ -235,6 +968,8 @@ func (s *Store) GetScreenshotPath(id int64) (string, error) {
 func (s *Store) GetScreenshot(id int64) (*models.Screenshot, error) {
 	var sc models.Screenshot
 	var ts time.Time
+	var endClass, endTitle sql.NullString
+	var expiresAt sql.NullTime
 
 	err := s.db.QueryRow(`
 		SELECT
@@ -242,13 +977,23 @@ func (s *Store) GetScreenshot(id int64) (*models.Screenshot, error) {
 			capture_ts, capture_timezone, capture_hostname, capture_user, capture_command, capture_version,
 			active_window_address, active_window_class, active_window_title, active_window_pid,
 			active_window_floating, active_window_fullscreen, active_window_xwayland, active_window_pinned,
-			workspace_id, workspace_name, workspace_monitor, workspace_windows, workspace_has_fullscreen, workspace_last_window_title
+			workspace_id, workspace_name, workspace_monitor, workspace_windows, workspace_has_fullscreen, workspace_last_window_title,
+			COALESCE(session_id, 0), COALESCE((SELECT name FROM sessions WHERE sessions.id = session_id), ''),
+			COALESCE(media_type, 'image'), COALESCE(duration, 0), COALESCE(size, 0), COALESCE(poster_path, ''),
+			end_window_class, end_window_title,
+			COALESCE(mime_type, ''), COALESCE(width, 0), COALESCE(height, 0),
+			COALESCE(origin, 'capture'), expires_at, COALESCE(app_rule, '')
 		FROM screenshots WHERE id = ?`, id).Scan(
 		&sc.ID, &sc.FilePath,
 		&ts, &sc.Capture.Timezone, &sc.Capture.Hostname, &sc.Capture.User, &sc.Capture.Command, &sc.Capture.Version,
 		&sc.ActiveWindow.Address, &sc.ActiveWindow.Class, &sc.ActiveWindow.Title, &sc.ActiveWindow.Pid,
 		&sc.ActiveWindow.State.Floating, &sc.ActiveWindow.State.Fullscreen, &sc.ActiveWindow.State.Xwayland, &sc.ActiveWindow.State.Pinned,
 		&sc.Workspace.ID, &sc.Workspace.Name, &sc.Workspace.Monitor, &sc.Workspace.Windows, &sc.Workspace.HasFullscreen, &sc.Workspace.LastWindowTitle,
+		&sc.SessionID, &sc.Session,
+		&sc.MediaType, &sc.Duration, &sc.Size, &sc.PosterPath,
+		&endClass, &endTitle,
+		&sc.MimeType, &sc.Width, &sc.Height,
+		&sc.Origin, &expiresAt, &sc.AppRule,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("screenshot with ID %d not found", id)
@@ -257,6 +1002,14 @@ func (s *Store) GetScreenshot(id int64) (*models.Screenshot, error) {
 		return nil, fmt.Errorf("failed to query screenshot: %w", err)
 	}
 	sc.Capture.Ts = ts
+	if expiresAt.Valid {
+		sc.ExpiresAt = &expiresAt.Time
+	}
+	sc.ActiveWindow.Title = s.open(sc.ActiveWindow.Title)
+	sc.Workspace.LastWindowTitle = s.open(sc.Workspace.LastWindowTitle)
+	if endClass.Valid {
+		sc.EndWindow = &models.ActiveWindow{Class: endClass.String, Title: s.open(endTitle.String)}
+	}
 
 	rows, err := s.db.Query("SELECT address, class, title, pid, workspace_id FROM clients WHERE screenshot_id = ?", id)
 	if err != nil {
@@ -269,8 +1022,110 @@ func (s *Store) GetScreenshot(id int64) (*models.Screenshot, error) {
 		if err := rows.Scan(&c.Address, &c.Class, &c.Title, &c.Pid, &c.WorkspaceID); err != nil {
 			return nil, fmt.Errorf("failed to scan client: %w", err)
 		}
+		c.Class = s.open(c.Class)
+		c.Title = s.open(c.Title)
 		sc.Clients = append(sc.Clients, c)
 	}
 
+	redactionRows, err := s.db.Query("SELECT rule, count FROM redactions WHERE screenshot_id = ? ORDER BY id", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query redactions: %w", err)
+	}
+	defer redactionRows.Close()
+
+	for redactionRows.Next() {
+		var r models.Redaction
+		if err := redactionRows.Scan(&r.Rule, &r.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan redaction: %w", err)
+		}
+		sc.Redactions = append(sc.Redactions, r)
+	}
+
+	sc.Revisions, err = s.ListRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	albumRows, err := s.db.Query(`
+		SELECT a.name FROM albums a
+		JOIN album_screenshots m ON m.album_id = a.id
+		WHERE m.screenshot_id = ?
+		ORDER BY a.name`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query albums: %w", err)
+	}
+	defer albumRows.Close()
+
+	for albumRows.Next() {
+		var name string
+		if err := albumRows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan album: %w", err)
+		}
+		sc.Albums = append(sc.Albums, name)
+	}
+
+	sc.Shares, err = s.ListShares(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &sc, nil
 }
+
+// StartSession returns the ID of the named session, creating it if it
+// does not exist yet so later runs can append to it.
+func (s *Store) StartSession(name string) (int64, error) {
+	if _, err := s.db.Exec("INSERT OR IGNORE INTO sessions (name, created_at) VALUES (?, ?)", name, time.Now()); err != nil {
+		return 0, fmt.Errorf("failed to create session: %w", err)
+	}
+	var id int64
+	if err := s.db.QueryRow("SELECT id FROM sessions WHERE name = ?", name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to query session: %w", err)
+	}
+	return id, nil
+}
+
+// ListSessions returns all sessions with their capture counts, newest
+// first.
+func (s *Store) ListSessions() ([]models.Session, error) {
+	rows, err := s.db.Query(`
+		SELECT se.id, se.name, se.created_at, COUNT(sc.id), MIN(sc.capture_ts), MAX(sc.capture_ts)
+		FROM sessions se
+		LEFT JOIN screenshots sc ON sc.session_id = se.id
+		GROUP BY se.id
+		ORDER BY se.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var se models.Session
+		var createdAt time.Time
+		var first, last sql.NullString
+		if err := rows.Scan(&se.ID, &se.Name, &createdAt, &se.Count, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		se.CreatedAt = createdAt
+		se.First = parseTimestamp(first.String)
+		se.Last = parseTimestamp(last.String)
+		sessions = append(sessions, se)
+	}
+	return sessions, nil
+}
+
+// parseTimestamp reads a DATETIME returned by an aggregate, which the
+// driver hands back as the stored text rather than time.Time.
+func parseTimestamp(v string) time.Time {
+	// Values written from time.Now() may carry a monotonic clock suffix.
+	if i := strings.Index(v, " m="); i >= 0 {
+		v = v[:i]
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999 -0700 MST", "2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"orego/pkg/models"
+)
+
+// AddShare records a link produced by uploading a screenshot.
+func (s *Store) AddShare(screenshotID int64, target, url string) error {
+	_, err := s.db.Exec("INSERT INTO shares (screenshot_id, target, url, created_at) VALUES (?, ?, ?, ?)",
+		screenshotID, target, url, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record share: %w", err)
+	}
+	return nil
+}
+
+// ListShares returns the uploads of a screenshot, oldest first.
+func (s *Store) ListShares(screenshotID int64) ([]models.Share, error) {
+	rows, err := s.db.Query("SELECT id, target, url, created_at FROM shares WHERE screenshot_id = ? ORDER BY id", screenshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []models.Share
+	for rows.Next() {
+		var sh models.Share
+		if err := rows.Scan(&sh.ID, &sh.Target, &sh.URL, &sh.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		shares = append(shares, sh)
+	}
+	return shares, rows.Err()
+}
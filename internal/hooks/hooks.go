@@ -0,0 +1,186 @@
+// Package hooks runs user commands on capture lifecycle events.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+// Events, named as in the hooks config section.
+const (
+	PreCapture = "pre_capture"
+	PostSave   = "post_save"
+	PostDelete = "post_delete"
+)
+
+// Failure policies for HookConfig.OnError.
+const (
+	OnErrorWarn   = "warn"
+	OnErrorIgnore = "ignore"
+	OnErrorAbort  = "abort"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Run runs the hooks for event in order. Synchronous hooks are killed
+// after their timeout; async hooks are started in their own session and
+// left running. Failures are handled by each hook's on_error policy: the
+// returned error is non-nil only if a hook with "abort" failed, and no
+// further hooks run after it.
+func Run(event string, hooks []config.HookConfig, sc *models.Screenshot) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(sc)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", event, err)
+	}
+	env := append(os.Environ(), Env(event, sc)...)
+
+	for _, h := range hooks {
+		err := runOne(h, payload, env)
+		if err == nil {
+			continue
+		}
+		switch h.OnError {
+		case OnErrorIgnore:
+		case OnErrorAbort:
+			return fmt.Errorf("%s hook %s: %w", event, h.Cmd, err)
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: %s hook %s: %v\n", event, h.Cmd, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the hooks of every event without running them.
+func Validate(cfg config.HooksConfig) error {
+	events := map[string][]config.HookConfig{
+		PreCapture: cfg.PreCapture,
+		PostSave:   cfg.PostSave,
+		PostDelete: cfg.PostDelete,
+	}
+	for event, hooks := range events {
+		for i, h := range hooks {
+			if h.Cmd == "" {
+				return fmt.Errorf("hooks.%s[%d]: cmd is empty", event, i)
+			}
+			switch h.OnError {
+			case "", OnErrorWarn, OnErrorIgnore, OnErrorAbort:
+			default:
+				return fmt.Errorf("hooks.%s[%d]: invalid on_error %q (supported: warn, ignore, abort)", event, i, h.OnError)
+			}
+			if _, err := timeout(h); err != nil {
+				return fmt.Errorf("hooks.%s[%d]: %w", event, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Env returns the OREGO_* variables describing sc.
+func Env(event string, sc *models.Screenshot) []string {
+	env := []string{
+		"OREGO_EVENT=" + event,
+		"OREGO_ID=" + strconv.FormatInt(sc.ID, 10),
+		"OREGO_FILE=" + sc.FilePath,
+		"OREGO_MIME_TYPE=" + sc.MimeType,
+		"OREGO_MEDIA_TYPE=" + sc.MediaType,
+		"OREGO_CLASS=" + sc.ActiveWindow.Class,
+		"OREGO_TITLE=" + sc.ActiveWindow.Title,
+		"OREGO_WORKSPACE=" + sc.Workspace.Name,
+		"OREGO_MONITOR=" + sc.Workspace.Monitor,
+		"OREGO_SESSION=" + sc.Session,
+		"OREGO_ALBUMS=" + strings.Join(sc.Albums, ","),
+	}
+	if !sc.Capture.Ts.IsZero() {
+		env = append(env, "OREGO_TIMESTAMP="+sc.Capture.Ts.Format(time.RFC3339))
+	}
+	return env
+}
+
+func timeout(h config.HookConfig) (time.Duration, error) {
+	if h.Timeout == "" {
+		return defaultTimeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", h.Timeout)
+	}
+	return d, nil
+}
+
+func runOne(h config.HookConfig, payload []byte, env []string) error {
+	if h.Cmd == "" {
+		return errors.New("cmd is empty")
+	}
+	if h.Async {
+		return start(h, payload, env)
+	}
+
+	d, err := timeout(h)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	// Hook output goes to stderr, stdout is reserved for OreGo's JSON.
+	cmd := exec.CommandContext(ctx, h.Cmd, h.Args...)
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// Kill the whole group so shell pipelines do not linger.
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", d)
+	}
+	return err
+}
+
+// start launches an async hook. The payload is handed over through an
+// unlinked temp file so the hook can read it after OreGo has exited.
+func start(h config.HookConfig, payload []byte, env []string) error {
+	f, err := os.CreateTemp("", "orego-hook-*.json")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+
+	if _, err := f.Write(payload); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(h.Cmd, h.Args...)
+	cmd.Env = env
+	cmd.Stdin = f
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,110 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats accepted by storage.format.
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+var extensions = map[string]string{
+	FormatPNG:  ".png",
+	FormatJPEG: ".jpg",
+	FormatWebP: ".webp",
+	FormatAVIF: ".avif",
+}
+
+// NormalizeFormat lower-cases a format name and maps aliases such as
+// "jpg". It returns an error for unsupported formats.
+func NormalizeFormat(format string) (string, error) {
+	f := strings.ToLower(strings.TrimSpace(format))
+	if f == "jpg" {
+		f = FormatJPEG
+	}
+	if _, ok := extensions[f]; !ok {
+		return "", fmt.Errorf("unsupported image format %q (supported: png, jpeg, webp, avif)", format)
+	}
+	return f, nil
+}
+
+// Extension returns the file extension, including the dot, for a
+// normalized format.
+func Extension(format string) string {
+	return extensions[format]
+}
+
+// FormatOf returns the storage format matching path's extension, or png
+// if the extension is unknown.
+func FormatOf(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for format, e := range extensions {
+		if e == ext {
+			return format
+		}
+	}
+	if ext == ".jpeg" {
+		return FormatJPEG
+	}
+	return FormatPNG
+}
+
+// Dimensions reads the size of an image without decoding the pixels.
+func Dimensions(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Encode re-encodes the image at src (png or jpeg) as png or jpeg at dst. quality (1-100)
+// only applies to jpeg; png is written with the best compression.
+func Encode(src, dst, format string, quality int) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", src, err)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatPNG:
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		err = enc.Encode(out, img)
+	case FormatJPEG:
+		err = jpeg.Encode(out, img, &jpeg.Options{Quality: max(1, min(quality, 100))})
+	default:
+		err = fmt.Errorf("%s needs an encoder command in storage.encoders", format)
+	}
+	if err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to encode %s: %w", dst, err)
+	}
+	return out.Close()
+}
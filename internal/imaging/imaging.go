@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// LoadPNG decodes a PNG file into a mutable RGBA image.
+func LoadPNG(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	if rgba, ok := src.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	b := src.Bounds()
+	rgba := image.NewRGBA(b)
+	draw.Draw(rgba, b, src, b.Min, draw.Src)
+	return rgba, nil
+}
+
+// SavePNG writes img to path, replacing any existing file.
+func SavePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// Pixelate replaces r with blocks of size block filled with their average colour.
+func Pixelate(img *image.RGBA, r image.Rectangle, block int) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() {
+		return
+	}
+	if block < 2 {
+		block = 2
+	}
+
+	for y := r.Min.Y; y < r.Max.Y; y += block {
+		for x := r.Min.X; x < r.Max.X; x += block {
+			cell := image.Rect(x, y, x+block, y+block).Intersect(r)
+			draw.Draw(img, cell, &image.Uniform{average(img, cell)}, image.Point{}, draw.Src)
+		}
+	}
+}
+
+func average(img *image.RGBA, r image.Rectangle) color.RGBA {
+	var sr, sg, sb, sa, n uint32
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			sr += uint32(c.R)
+			sg += uint32(c.G)
+			sb += uint32(c.B)
+			sa += uint32(c.A)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.RGBA{}
+	}
+	return color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), uint8(sa / n)}
+}
+
+// Blur applies a strong box blur to r. Three passes approximate a
+// gaussian; the result is not meant to be reversible.
+func Blur(img *image.RGBA, r image.Rectangle, radius int) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() || radius < 1 {
+		return
+	}
+
+	for pass := 0; pass < 3; pass++ {
+		boxBlur(img, r, radius, true)
+		boxBlur(img, r, radius, false)
+	}
+}
+
+func boxBlur(img *image.RGBA, r image.Rectangle, radius int, horizontal bool) {
+	outer, inner := r.Dy(), r.Dx()
+	if !horizontal {
+		outer, inner = inner, outer
+	}
+	at := func(o, i int) (int, int) {
+		if horizontal {
+			return r.Min.X + i, r.Min.Y + o
+		}
+		return r.Min.X + o, r.Min.Y + i
+	}
+
+	line := make([]color.RGBA, inner)
+	for o := 0; o < outer; o++ {
+		for i := range line {
+			line[i] = img.RGBAAt(at(o, i))
+		}
+
+		var sr, sg, sb, sa, n int
+		add := func(c color.RGBA, sign int) {
+			sr += sign * int(c.R)
+			sg += sign * int(c.G)
+			sb += sign * int(c.B)
+			sa += sign * int(c.A)
+			n += sign
+		}
+		for i := 0; i < radius && i < inner; i++ {
+			add(line[i], 1)
+		}
+		for i := 0; i < inner; i++ {
+			if j := i + radius; j < inner {
+				add(line[j], 1)
+			}
+			if j := i - radius - 1; j >= 0 {
+				add(line[j], -1)
+			}
+			x, y := at(o, i)
+			img.SetRGBA(x, y, color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), uint8(sa / n)})
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package imaging
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Fallbacks for systems without a MIME database.
+var knownTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".webp": "image/webp",
+	".avif": "image/avif",
+	".mp4":  "video/mp4",
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+}
+
+// MimeType guesses a file's MIME type from its extension, defaulting to
+// PNG which is what grim writes.
+func MimeType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if t, ok := knownTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		t, _, _ = strings.Cut(t, ";")
+		return t
+	}
+	return "image/png"
+}
+
+// IsVideo reports whether path looks like a screen recording.
+func IsVideo(path string) bool {
+	return strings.HasPrefix(MimeType(path), "video/")
+}
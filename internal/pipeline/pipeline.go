@@ -0,0 +1,216 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+// Stage types understood in capture.pipeline.
+const (
+	StageEditor    = "editor"
+	StageNone      = "none"
+	StageSwappy    = "swappy"
+	StageCustom    = "custom"
+	StageEditLater = "edit-later"
+)
+
+// ErrDiscarded means a stage decided the capture should not be kept.
+var ErrDiscarded = errors.New("screenshot discarded")
+
+type Options struct {
+	// Editor is used by "editor" stages and for edit-later.
+	Editor config.EditorConfig
+	// Screenshot is passed to stage templates; it may be nil.
+	Screenshot *models.Screenshot
+	// Timeout bounds how long to wait for an editor's output after it exits.
+	Timeout time.Duration
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+type Outcome struct {
+	// EditLater is set when the pipeline ended with an edit-later stage;
+	// the caller should open the editor on the saved file.
+	EditLater bool
+}
+
+// Validate checks stage types and ordering without running anything.
+func Validate(stages []config.StageConfig) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("capture.pipeline is empty")
+	}
+	for i, st := range stages {
+		switch st.Type {
+		case StageEditor, StageNone, StageSwappy:
+		case StageCustom:
+			if st.Cmd == "" {
+				return fmt.Errorf("pipeline stage %d: custom stage needs a cmd", i+1)
+			}
+		case StageEditLater:
+			if i != len(stages)-1 {
+				return fmt.Errorf("pipeline stage %d: edit-later must be the last stage", i+1)
+			}
+		default:
+			return fmt.Errorf("pipeline stage %d: unknown type %q", i+1, st.Type)
+		}
+	}
+	return nil
+}
+
+// Run passes input through every stage and writes the result to target.
+// It returns ErrDiscarded if an editor produced no output or a custom
+// stage exited with status 1.
+func Run(stages []config.StageConfig, input, target string, opts Options) (Outcome, error) {
+	var outcome Outcome
+	if err := Validate(stages); err != nil {
+		return outcome, err
+	}
+
+	current := input
+	var intermediates []string
+	defer func() {
+		for _, p := range intermediates {
+			os.Remove(p)
+		}
+	}()
+
+	for i, st := range stages {
+		// Output paths must not exist yet, otherwise waiting for the
+		// editor to save would succeed immediately.
+		output := filepath.Join(os.TempDir(), fmt.Sprintf("orego-stage-%d-%d%s", time.Now().UnixNano(), i, filepath.Ext(target)))
+
+		var err error
+		switch st.Type {
+		case StageNone:
+			continue
+		case StageEditLater:
+			outcome.EditLater = true
+			continue
+		case StageEditor:
+			cmd, args := opts.Editor.Cmd, opts.Editor.Args
+			if st.Cmd != "" {
+				cmd, args = st.Cmd, st.Args
+			}
+			err = runEditor(cmd, args, current, output, opts)
+		case StageSwappy:
+			cmd, args := "swappy", []string{"-f", "{{.Input}}", "-o", "{{.Output}}"}
+			if st.Cmd != "" {
+				cmd, args = st.Cmd, st.Args
+			}
+			err = runEditor(cmd, args, current, output, opts)
+		case StageCustom:
+			var produced bool
+			produced, err = runCustom(st, current, output, opts)
+			if err == nil && !produced {
+				continue
+			}
+		}
+		if err != nil {
+			return outcome, err
+		}
+
+		intermediates = append(intermediates, output)
+		current = output
+	}
+
+	if err := copyFile(current, target); err != nil {
+		return outcome, fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	return outcome, nil
+}
+
+// runEditor runs an interactive editor and waits for it to write output.
+func runEditor(cmd string, args []string, input, output string, opts Options) error {
+	data := config.NewTemplateData(opts.Screenshot)
+	data.Input, data.Output = input, output
+	rendered, err := config.RenderArgs(args, data)
+	if err != nil {
+		return fmt.Errorf("failed to render editor args: %w", err)
+	}
+
+	watcher, err := watchFile(output)
+	if err != nil {
+		return fmt.Errorf("failed to watch for editor output: %w", err)
+	}
+	defer watcher.Close()
+
+	editor := exec.Command(cmd, rendered...)
+	editor.Stdin = opts.Stdin
+	editor.Stdout = opts.Stdout
+	editor.Stderr = opts.Stderr
+	if err := editor.Run(); err != nil {
+		fmt.Fprintf(opts.Stderr, "Editor exited with: %v\n", err)
+	}
+
+	if !watcher.Wait(opts.Timeout) {
+		return ErrDiscarded
+	}
+	return nil
+}
+
+// runCustom runs a non-interactive stage. Exit status 0 keeps the capture
+// (using output if the command wrote it), 1 discards it and anything else
+// is an error. It reports whether output was produced.
+func runCustom(st config.StageConfig, input, output string, opts Options) (bool, error) {
+	data := config.NewTemplateData(opts.Screenshot)
+	data.Input, data.Output = input, output
+	rendered, err := config.RenderArgs(st.Args, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to render %s args: %w", st.Cmd, err)
+	}
+
+	c := exec.Command(st.Cmd, rendered...)
+	c.Stdin = opts.Stdin
+	c.Stdout = opts.Stdout
+	c.Stderr = opts.Stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, ErrDiscarded
+		}
+		return false, fmt.Errorf("%s failed: %w", st.Cmd, err)
+	}
+
+	_, err = os.Stat(output)
+	return err == nil, nil
+}
+
+// EditLaterCommand builds the editor invocation that annotates the saved
+// file of sc in place.
+func EditLaterCommand(editor config.EditorConfig, sc *models.Screenshot) (*exec.Cmd, error) {
+	data := config.NewTemplateData(sc)
+	data.Input, data.Output = sc.FilePath, sc.FilePath
+	args, err := config.RenderArgs(editor.Args, data)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(editor.Cmd, args...), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
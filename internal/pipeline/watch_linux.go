@@ -0,0 +1,77 @@
+//go:build linux
+
+package pipeline
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileWatcher reports when a file is fully written to its directory, using
+// inotify so editors that save after exiting are picked up immediately.
+type fileWatcher struct {
+	fd   int
+	path string
+}
+
+func watchFile(path string) (*fileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := unix.InotifyAddWatch(fd, filepath.Dir(path), unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &fileWatcher{fd: fd, path: path}, nil
+}
+
+// Wait blocks until the file has been written or timeout elapses and
+// reports whether the file exists.
+func (w *fileWatcher) Wait(timeout time.Duration) bool {
+	name := []byte(filepath.Base(w.path))
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+
+	for {
+		if _, err := os.Stat(w.path); err == nil {
+			return true
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(remaining.Milliseconds())+1)
+		if err != nil && err != unix.EINTR {
+			return false
+		}
+		if n <= 0 {
+			continue
+		}
+
+		read, err := unix.Read(w.fd, buf)
+		if err != nil {
+			continue
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= read; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if bytes.Equal(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"), name) {
+				return true
+			}
+			offset = nameEnd
+		}
+	}
+}
+
+func (w *fileWatcher) Close() error {
+	return unix.Close(w.fd)
+}
@@ -0,0 +1,34 @@
+//go:build !linux
+
+package pipeline
+
+import (
+	"os"
+	"time"
+)
+
+// fileWatcher falls back to polling where inotify is unavailable.
+type fileWatcher struct {
+	path string
+}
+
+func watchFile(path string) (*fileWatcher, error) {
+	return &fileWatcher{path: path}, nil
+}
+
+func (w *fileWatcher) Wait(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := os.Stat(w.path); err == nil {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (w *fileWatcher) Close() error {
+	return nil
+}
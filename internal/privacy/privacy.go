@@ -0,0 +1,152 @@
+package privacy
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"regexp"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+type Action string
+
+const (
+	// Block refuses the capture entirely.
+	Block Action = "block"
+	// NoMetadata saves the image but drops window titles and clients.
+	NoMetadata Action = "no-metadata"
+	// BlurWindow blurs the geometry of every matching client.
+	BlurWindow Action = "blur-window"
+)
+
+// Rule is the compiled form of config.PrivacyRule. Nil patterns match
+// anything, but at least one pattern is required.
+type Rule struct {
+	Name   string
+	Class  *regexp.Regexp
+	Title  *regexp.Regexp
+	Action Action
+}
+
+// Decision is the combined outcome of all rules for one capture.
+type Decision struct {
+	BlockedBy  string
+	NoMetadata bool
+	Blur       []models.Geometry
+	Fired      []string
+}
+
+func CompileRules(rules []config.PrivacyRule) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for i, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		rule := Rule{Name: name, Action: Action(r.Action)}
+		switch rule.Action {
+		case Block, NoMetadata, BlurWindow:
+		default:
+			return nil, fmt.Errorf("privacy %s: unknown action %q (block, no-metadata, blur-window)", name, r.Action)
+		}
+		if r.Class == "" && r.Title == "" {
+			return nil, fmt.Errorf("privacy %s: needs a class or title pattern", name)
+		}
+
+		var err error
+		if r.Class != "" {
+			if rule.Class, err = regexp.Compile(r.Class); err != nil {
+				return nil, fmt.Errorf("privacy %s: invalid class pattern: %w", name, err)
+			}
+		}
+		if r.Title != "" {
+			if rule.Title, err = regexp.Compile(r.Title); err != nil {
+				return nil, fmt.Errorf("privacy %s: invalid title pattern: %w", name, err)
+			}
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+func (r Rule) matches(class, title string) bool {
+	if r.Class != nil && !r.Class.MatchString(class) {
+		return false
+	}
+	if r.Title != nil && !r.Title.MatchString(title) {
+		return false
+	}
+	return true
+}
+
+// Evaluate checks every rule against the active window and each client.
+func Evaluate(rules []Rule, sc *models.Screenshot) Decision {
+	var d Decision
+
+	for _, rule := range rules {
+		fired := false
+
+		if rule.matches(sc.ActiveWindow.Class, sc.ActiveWindow.Title) && sc.ActiveWindow.Class+sc.ActiveWindow.Title != "" {
+			fired = true
+			if rule.Action == BlurWindow {
+				if g := clientGeometry(sc, sc.ActiveWindow.Address); g != nil {
+					d.Blur = append(d.Blur, *g)
+				}
+			}
+		}
+
+		for _, c := range sc.Clients {
+			if !rule.matches(c.Class, c.Title) {
+				continue
+			}
+			fired = true
+			if rule.Action == BlurWindow && c.Geometry != nil && c.Address != sc.ActiveWindow.Address {
+				d.Blur = append(d.Blur, *c.Geometry)
+			}
+		}
+
+		if !fired {
+			continue
+		}
+		d.Fired = append(d.Fired, rule.Name)
+		switch rule.Action {
+		case Block:
+			if d.BlockedBy == "" {
+				d.BlockedBy = rule.Name
+			}
+		case NoMetadata:
+			d.NoMetadata = true
+		}
+	}
+	return d
+}
+
+func clientGeometry(sc *models.Screenshot, address string) *models.Geometry {
+	for _, c := range sc.Clients {
+		if c.Address == address {
+			return c.Geometry
+		}
+	}
+	return nil
+}
+
+// StripMetadata removes titles and the client list from sc.
+func StripMetadata(sc *models.Screenshot) {
+	sc.ActiveWindow.Title = ""
+	sc.Workspace.LastWindowTitle = ""
+	sc.Clients = nil
+}
+
+// ImageRect maps a layout geometry to pixel coordinates of an image that
+// covers region at the given scale.
+func ImageRect(g models.Geometry, region models.Geometry, scale float64) image.Rectangle {
+	if scale <= 0 {
+		scale = 1
+	}
+	px := func(v int) int { return int(math.Round(float64(v) * scale)) }
+	x0, y0 := px(g.X-region.X), px(g.Y-region.Y)
+	return image.Rect(x0, y0, x0+px(g.Width), y0+px(g.Height))
+}
@@ -0,0 +1,228 @@
+package privacy
+
+import (
+	"image"
+	"slices"
+	"strings"
+	"testing"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+// desktop is what Hyprland reports for a workspace with a browser in
+// focus, a password manager and a chat client beside it.
+func desktop() *models.Screenshot {
+	return &models.Screenshot{
+		ActiveWindow: models.ActiveWindow{Address: "0x1", Class: "firefox", Title: "Online Banking - Mozilla Firefox"},
+		Workspace: models.Workspace{
+			ID: 2, Name: "2", Monitor: "DP-1", Windows: 3, LastWindowTitle: "Signal",
+		},
+		Clients: []models.Client{
+			{Address: "0x1", Class: "firefox", Title: "Online Banking - Mozilla Firefox", WorkspaceID: 2,
+				Geometry: &models.Geometry{X: 0, Y: 0, Width: 1280, Height: 1440}},
+			{Address: "0x2", Class: "org.keepassxc.KeePassXC", Title: "Passwords.kdbx - KeePassXC", WorkspaceID: 2,
+				Geometry: &models.Geometry{X: 1280, Y: 0, Width: 1280, Height: 720}},
+			{Address: "0x3", Class: "signal", Title: "Signal", WorkspaceID: 2,
+				Geometry: &models.Geometry{X: 1280, Y: 720, Width: 1280, Height: 720}},
+		},
+	}
+}
+
+func compile(t *testing.T, rules ...config.PrivacyRule) []Rule {
+	t.Helper()
+	compiled, err := CompileRules(rules)
+	if err != nil {
+		t.Fatalf("CompileRules: %v", err)
+	}
+	return compiled
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      []config.PrivacyRule
+		blockedBy  string
+		noMetadata bool
+		blur       []models.Geometry
+		fired      []string
+	}{
+		{
+			name:  "nothing matches",
+			rules: []config.PrivacyRule{{Name: "bitwarden", Class: "(?i)bitwarden", Action: "block"}},
+		},
+		{
+			name:      "block on active title",
+			rules:     []config.PrivacyRule{{Name: "banking", Title: "(?i)bank", Action: "block"}},
+			blockedBy: "banking",
+			fired:     []string{"banking"},
+		},
+		{
+			name:      "block on background client",
+			rules:     []config.PrivacyRule{{Name: "keepass", Class: "(?i)keepassxc", Action: "block"}},
+			blockedBy: "keepass",
+			fired:     []string{"keepass"},
+		},
+		{
+			name: "first block wins",
+			rules: []config.PrivacyRule{
+				{Name: "keepass", Class: "(?i)keepassxc", Action: "block"},
+				{Name: "banking", Title: "(?i)bank", Action: "block"},
+			},
+			blockedBy: "keepass",
+			fired:     []string{"keepass", "banking"},
+		},
+		{
+			name:       "strip metadata",
+			rules:      []config.PrivacyRule{{Name: "chat", Class: "^signal$", Action: "no-metadata"}},
+			noMetadata: true,
+			fired:      []string{"chat"},
+		},
+		{
+			name:  "class and title must both match",
+			rules: []config.PrivacyRule{{Name: "chat", Class: "^signal$", Title: "Bank", Action: "block"}},
+		},
+		{
+			name:  "blur background window",
+			rules: []config.PrivacyRule{{Name: "keepass", Class: "(?i)keepassxc", Action: "blur-window"}},
+			blur:  []models.Geometry{{X: 1280, Y: 0, Width: 1280, Height: 720}},
+			fired: []string{"keepass"},
+		},
+		{
+			name:  "blur active window once",
+			rules: []config.PrivacyRule{{Name: "browser", Class: "^firefox$", Action: "blur-window"}},
+			blur:  []models.Geometry{{X: 0, Y: 0, Width: 1280, Height: 1440}},
+			fired: []string{"browser"},
+		},
+		{
+			name: "combined",
+			rules: []config.PrivacyRule{
+				{Name: "keepass", Class: "(?i)keepassxc", Action: "blur-window"},
+				{Name: "chat", Class: "^signal$", Action: "blur-window"},
+				{Name: "banking", Title: "(?i)bank", Action: "no-metadata"},
+			},
+			noMetadata: true,
+			blur: []models.Geometry{
+				{X: 1280, Y: 0, Width: 1280, Height: 720},
+				{X: 1280, Y: 720, Width: 1280, Height: 720},
+			},
+			fired: []string{"keepass", "chat", "banking"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Evaluate(compile(t, tt.rules...), desktop())
+			if d.BlockedBy != tt.blockedBy {
+				t.Errorf("BlockedBy = %q, want %q", d.BlockedBy, tt.blockedBy)
+			}
+			if d.NoMetadata != tt.noMetadata {
+				t.Errorf("NoMetadata = %v, want %v", d.NoMetadata, tt.noMetadata)
+			}
+			if !slices.Equal(d.Blur, tt.blur) {
+				t.Errorf("Blur = %v, want %v", d.Blur, tt.blur)
+			}
+			if !slices.Equal(d.Fired, tt.fired) {
+				t.Errorf("Fired = %v, want %v", d.Fired, tt.fired)
+			}
+		})
+	}
+}
+
+func TestEvaluateEmptyDesktop(t *testing.T) {
+	// An empty active window (desktop focused) must not match a
+	// title-only rule that accepts anything.
+	rules := compile(t, config.PrivacyRule{Name: "any", Title: ".*", Action: "block"})
+	if d := Evaluate(rules, &models.Screenshot{}); d.BlockedBy != "" {
+		t.Errorf("BlockedBy = %q on an empty desktop", d.BlockedBy)
+	}
+}
+
+func TestCompileRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    config.PrivacyRule
+		wantErr string
+	}{
+		{"unknown action", config.PrivacyRule{Class: "x", Action: "hide"}, `unknown action "hide"`},
+		{"no pattern", config.PrivacyRule{Name: "empty", Action: "block"}, "privacy empty: needs a class or title pattern"},
+		{"bad regex", config.PrivacyRule{Class: "(", Action: "block"}, "privacy rule 1: invalid class pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileRules([]config.PrivacyRule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	sc := desktop()
+	StripMetadata(sc)
+	if sc.ActiveWindow.Title != "" || sc.Workspace.LastWindowTitle != "" || sc.Clients != nil {
+		t.Errorf("titles or clients left after StripMetadata: %+v", sc)
+	}
+	if sc.ActiveWindow.Class != "firefox" || sc.Workspace.Monitor != "DP-1" {
+		t.Errorf("StripMetadata removed more than titles: %+v", sc)
+	}
+}
+
+func TestImageRect(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      models.Geometry
+		region models.Geometry
+		scale  float64
+		want   image.Rectangle
+	}{
+		{
+			name:   "origin, scale 1",
+			g:      models.Geometry{X: 100, Y: 50, Width: 200, Height: 100},
+			region: models.Geometry{Width: 1920, Height: 1080},
+			scale:  1,
+			want:   image.Rect(100, 50, 300, 150),
+		},
+		{
+			name:   "zero scale is 1",
+			g:      models.Geometry{X: 10, Y: 10, Width: 10, Height: 10},
+			region: models.Geometry{Width: 100, Height: 100},
+			want:   image.Rect(10, 10, 20, 20),
+		},
+		{
+			name:   "HiDPI",
+			g:      models.Geometry{X: 100, Y: 50, Width: 200, Height: 100},
+			region: models.Geometry{Width: 1280, Height: 720},
+			scale:  2,
+			want:   image.Rect(200, 100, 600, 300),
+		},
+		{
+			name:   "second monitor offset",
+			g:      models.Geometry{X: 2660, Y: 100, Width: 400, Height: 300},
+			region: models.Geometry{X: 2560, Width: 1920, Height: 1080},
+			scale:  1,
+			want:   image.Rect(100, 100, 500, 400),
+		},
+		{
+			name:   "fractional scale rounds",
+			g:      models.Geometry{X: 1281, Y: 1, Width: 3, Height: 3},
+			region: models.Geometry{X: 1280, Width: 1707, Height: 960},
+			scale:  1.5,
+			want:   image.Rect(2, 2, 7, 7),
+		},
+		{
+			name:   "window left of the region",
+			g:      models.Geometry{X: -100, Y: 0, Width: 300, Height: 100},
+			region: models.Geometry{Width: 1920, Height: 1080},
+			scale:  1,
+			want:   image.Rect(-100, 0, 200, 100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageRect(tt.g, tt.region, tt.scale); got != tt.want {
+				t.Errorf("ImageRect = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
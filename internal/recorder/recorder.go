@@ -0,0 +1,200 @@
+package recorder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+// A recording outlives the `orego record start` process, so everything
+// `record stop` needs is kept in a state file in the runtime directory.
+
+// ErrNotRecording is returned by Load when no recording is in progress.
+var ErrNotRecording = errors.New("no recording in progress")
+
+// State describes a running recording.
+type State struct {
+	Pid     int                `json:"pid"`
+	Output  string             `json:"output"`
+	Started time.Time          `json:"started"`
+	Context *models.Screenshot `json:"context"` // Window metadata at start
+
+	// NoMetadata is set when a privacy rule stripped the start context;
+	// the stop context is then dropped as well.
+	NoMetadata bool `json:"no_metadata,omitempty"`
+}
+
+func stateDir() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("orego-%d", os.Getuid()))
+	}
+	dir = filepath.Join(dir, "orego")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create runtime dir: %w", err)
+	}
+	return dir, nil
+}
+
+func statePath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recording.json"), nil
+}
+
+// LogPath is where the recorder's output goes while it runs detached.
+func LogPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recording.log"), nil
+}
+
+// Load returns the running recording. A state file left behind by a
+// recorder that is no longer alive is still returned so it can be saved.
+func Load() (*State, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotRecording
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse recording state: %w", err)
+	}
+	return &st, nil
+}
+
+func (st *State) Save() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Clear forgets the current recording.
+func Clear() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Start launches the recorder in its own session so it keeps running
+// after orego exits, and returns its PID.
+func Start(cmd string, args []string) (int, error) {
+	logPath, err := LogPath()
+	if err != nil {
+		return 0, err
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create recorder log: %w", err)
+	}
+	defer logFile.Close()
+
+	rec := exec.Command(cmd, args...)
+	rec.Stdout = logFile
+	rec.Stderr = logFile
+	rec.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := rec.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s: %w", cmd, err)
+	}
+
+	// Reap the child if it dies while we are still around, so Alive
+	// does not mistake a zombie for a running recorder.
+	exited := make(chan struct{})
+	go func() {
+		rec.Wait()
+		close(exited)
+	}()
+
+	// Recorders fail fast on bad arguments or a missing output; give them
+	// a moment so the error can be reported instead of a dead PID.
+	select {
+	case <-exited:
+		log, _ := os.ReadFile(logPath)
+		return 0, fmt.Errorf("%s exited immediately: %s", cmd, string(log))
+	case <-time.After(300 * time.Millisecond):
+	}
+	return rec.Process.Pid, nil
+}
+
+// Alive reports whether the process is still running.
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	// An exited recorder whose parent has not reaped it yet is a zombie.
+	if stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		if i := strings.LastIndexByte(string(stat), ')'); i >= 0 && i+2 < len(stat) {
+			return stat[i+2] != 'Z'
+		}
+	}
+	return true
+}
+
+// Stop asks the recorder to finish (SIGINT makes wf-recorder finalize the
+// file) and waits up to timeout for it to exit.
+func Stop(pid int, timeout time.Duration) error {
+	if !Alive(pid) {
+		return nil
+	}
+	if err := syscall.Kill(pid, syscall.SIGINT); err != nil {
+		return fmt.Errorf("failed to signal recorder: %w", err)
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !Alive(pid) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("recorder (pid %d) did not exit within %s", pid, timeout)
+}
+
+// Poster extracts a still frame from the recording.
+func Poster(cfg config.CommandConfig, input, output string) error {
+	args, err := config.RenderArgs(cfg.Args, config.TemplateData{
+		Input:  input,
+		Output: output,
+	})
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command(cfg.Cmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", cfg.Cmd, err, out)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package redact
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"orego/internal/config"
+)
+
+// Word is a single OCR word with its bounding box in image coordinates.
+type Word struct {
+	Text string
+	Box  image.Rectangle
+	Line string // block/paragraph/line key, words sharing it are on one line
+}
+
+// Rule is a compiled redaction rule.
+type Rule struct {
+	Name string
+	Re   *regexp.Regexp
+}
+
+// Match is a region that must be redacted because Rule matched there.
+type Match struct {
+	Rule string
+	Box  image.Rectangle
+}
+
+func CompileRules(rules []config.RedactionRule) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", r.Name, err)
+		}
+		compiled = append(compiled, Rule{Name: r.Name, Re: re})
+	}
+	return compiled, nil
+}
+
+// ParseTSV parses tesseract's TSV output, keeping only word-level rows.
+func ParseTSV(data []byte) ([]Word, error) {
+	var words []Word
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	header := true
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		// level page block par line word left top width height conf text
+		fields := strings.SplitN(scanner.Text(), "\t", 12)
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if text == "" {
+			continue
+		}
+
+		var nums [4]int
+		for i := range nums {
+			n, err := strconv.Atoi(fields[6+i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tsv row %q: %w", scanner.Text(), err)
+			}
+			nums[i] = n
+		}
+
+		words = append(words, Word{
+			Text: text,
+			Box:  image.Rect(nums[0], nums[1], nums[0]+nums[2], nums[1]+nums[3]),
+			Line: strings.Join(fields[1:5], "/"),
+		})
+	}
+	return words, scanner.Err()
+}
+
+// Find matches rules against each OCR line and returns the boxes of the
+// words covered by a match. Matching whole lines lets patterns span words,
+// e.g. card numbers printed in groups of four.
+func Find(words []Word, rules []Rule) []Match {
+	var matches []Match
+
+	for start := 0; start < len(words); {
+		end := start + 1
+		for end < len(words) && words[end].Line == words[start].Line {
+			end++
+		}
+		line := words[start:end]
+		start = end
+
+		var text strings.Builder
+		offsets := make([]int, len(line))
+		for i, w := range line {
+			if i > 0 {
+				text.WriteByte(' ')
+			}
+			offsets[i] = text.Len()
+			text.WriteString(w.Text)
+		}
+
+		for _, rule := range rules {
+			for _, loc := range rule.Re.FindAllStringIndex(text.String(), -1) {
+				var box image.Rectangle
+				for i, w := range line {
+					wordStart, wordEnd := offsets[i], offsets[i]+len(w.Text)
+					if wordEnd <= loc[0] || wordStart >= loc[1] {
+						continue
+					}
+					box = box.Union(w.Box)
+				}
+				if !box.Empty() {
+					matches = append(matches, Match{Rule: rule.Name, Box: box})
+				}
+			}
+		}
+	}
+	return matches
+}
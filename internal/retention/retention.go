@@ -0,0 +1,234 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"orego/internal/config"
+	"orego/pkg/models"
+)
+
+// Policy is the compiled form of config.RetentionConfig.
+type Policy struct {
+	MaxAge       time.Duration
+	MaxPerClass  int
+	MaxTotalSize int64
+	KeepTitles   []*regexp.Regexp
+	KeepClasses  []*regexp.Regexp
+}
+
+// Item is a screenshot considered for pruning together with its size on disk.
+type Item struct {
+	Screenshot models.Screenshot
+	Size       int64
+}
+
+// Decision records a screenshot selected for removal and why.
+type Decision struct {
+	Item
+	Reason string
+}
+
+func NewPolicy(cfg config.RetentionConfig) (Policy, error) {
+	var p Policy
+	var err error
+
+	if cfg.MaxAge != "" {
+		if p.MaxAge, err = ParseAge(cfg.MaxAge); err != nil {
+			return p, fmt.Errorf("invalid retention.max_age: %w", err)
+		}
+	}
+	if cfg.MaxTotalSize != "" {
+		if p.MaxTotalSize, err = ParseSize(cfg.MaxTotalSize); err != nil {
+			return p, fmt.Errorf("invalid retention.max_total_size: %w", err)
+		}
+	}
+	if cfg.MaxPerClass < 0 {
+		return p, fmt.Errorf("invalid retention.max_per_class: must not be negative")
+	}
+	p.MaxPerClass = cfg.MaxPerClass
+
+	if p.KeepTitles, err = compileAll(cfg.KeepTitles); err != nil {
+		return p, fmt.Errorf("invalid retention.keep_titles: %w", err)
+	}
+	if p.KeepClasses, err = compileAll(cfg.KeepClasses); err != nil {
+		return p, fmt.Errorf("invalid retention.keep_classes: %w", err)
+	}
+
+	return p, nil
+}
+
+// Empty reports whether the policy would never remove anything.
+func (p Policy) Empty() bool {
+	return p.MaxAge == 0 && p.MaxPerClass == 0 && p.MaxTotalSize == 0
+}
+
+// Evaluate returns the items that violate the policy, newest first.
+// Expired ephemeral entries are always removed. Rules are then applied
+// in order: age, per-class count, then total size.
+// Items matching a keep pattern are never removed but still count
+// towards the per-class and total size budgets.
+func (p Policy) Evaluate(items []Item, now time.Time) []Decision {
+	sorted := make([]Item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Screenshot.Capture.Ts.After(sorted[j].Screenshot.Capture.Ts)
+	})
+
+	removed := make(map[int64]string)
+
+	for _, it := range sorted {
+		if exp := it.Screenshot.ExpiresAt; exp != nil && now.After(*exp) {
+			removed[it.Screenshot.ID] = "ephemeral entry expired"
+		}
+	}
+
+	if p.MaxAge > 0 {
+		for _, it := range sorted {
+			if _, ok := removed[it.Screenshot.ID]; ok || p.protected(it) {
+				continue
+			}
+			if now.Sub(it.Screenshot.Capture.Ts) > p.MaxAge {
+				removed[it.Screenshot.ID] = fmt.Sprintf("older than %s", FormatAge(p.MaxAge))
+			}
+		}
+	}
+
+	if p.MaxPerClass > 0 {
+		perClass := make(map[string]int)
+		for _, it := range sorted {
+			if _, ok := removed[it.Screenshot.ID]; ok {
+				continue
+			}
+			class := it.Screenshot.ActiveWindow.Class
+			perClass[class]++
+			if perClass[class] > p.MaxPerClass && !p.protected(it) {
+				removed[it.Screenshot.ID] = fmt.Sprintf("more than %d screenshots of %q", p.MaxPerClass, classLabel(class))
+			}
+		}
+	}
+
+	if p.MaxTotalSize > 0 {
+		var total int64
+		for _, it := range sorted {
+			if _, ok := removed[it.Screenshot.ID]; ok {
+				continue
+			}
+			if total+it.Size > p.MaxTotalSize && !p.protected(it) {
+				removed[it.Screenshot.ID] = fmt.Sprintf("total size exceeds %s", FormatSize(p.MaxTotalSize))
+				continue
+			}
+			total += it.Size
+		}
+	}
+
+	decisions := make([]Decision, 0, len(removed))
+	for _, it := range sorted {
+		if reason, ok := removed[it.Screenshot.ID]; ok {
+			decisions = append(decisions, Decision{Item: it, Reason: reason})
+		}
+	}
+	return decisions
+}
+
+func (p Policy) protected(it Item) bool {
+	for _, re := range p.KeepTitles {
+		if re.MatchString(it.Screenshot.ActiveWindow.Title) {
+			return true
+		}
+	}
+	for _, re := range p.KeepClasses {
+		if re.MatchString(it.Screenshot.ActiveWindow.Class) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func classLabel(class string) string {
+	if class == "" {
+		return "(none)"
+	}
+	return class
+}
+
+// ParseAge parses a duration that additionally accepts day ("90d") and
+// week ("2w") suffixes.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+func FormatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+var sizeUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+	{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30}, {"t", 1 << 40},
+	{"b", 1},
+}
+
+// ParseSize parses a byte size such as "5GiB", "500MB" or "1048576".
+func ParseSize(s string) (int64, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range sizeUnits {
+		if n, ok := strings.CutSuffix(lower, u.suffix); ok {
+			lower = strings.TrimSpace(n)
+			mult = u.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(lower, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(v * float64(mult)), nil
+}
+
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
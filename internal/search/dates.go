@@ -0,0 +1,245 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range is a half-open time range [From, To) taken from a query.
+type Range struct {
+	From, To time.Time
+
+	// Wall ranges are calendar days, weeks, months or years. They compare
+	// the wall clock where the screenshot was taken, so "yesterday" means
+	// the day it was in the capture's timezone.
+	Wall bool
+}
+
+// Contains reports whether a screenshot taken at ts in timezone tz falls
+// in the range.
+func (r Range) Contains(ts time.Time, tz string) bool {
+	if r.Wall {
+		w := CaptureTime(ts, tz)
+		ts = time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), r.From.Location())
+	}
+	return !ts.Before(r.From) && ts.Before(r.To)
+}
+
+// CaptureTime returns ts on the capture's wall clock. Timestamps keep the
+// offset they were taken with; UTC ones are moved to tz if it names a
+// zone the system knows.
+func CaptureTime(ts time.Time, tz string) time.Time {
+	if _, offset := ts.Zone(); offset != 0 || tz == "" || tz == "UTC" {
+		return ts
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return ts.In(loc)
+	}
+	return ts
+}
+
+// Query is a search query with its date phrase taken out.
+type Query struct {
+	Text  string
+	Range *Range // nil without a date phrase
+}
+
+// ParseQuery pulls the first date phrase out of s, relative to now:
+//
+//	today, yesterday, monday (the last one, today included), last monday,
+//	this/last week|month|year, 2 hours ago, 3 days ago, a week ago,
+//	last 3 days, past 2 hours, in march, in march 2025, in 2025
+//
+// Weeks start on Monday. The rest of s is returned as Text.
+func ParseQuery(s string, now time.Time) Query {
+	tokens := strings.Fields(s)
+	lower := make([]string, len(tokens))
+	for i, t := range tokens {
+		lower[i] = strings.ToLower(t)
+	}
+
+	for i := range lower {
+		r, n := parseDatePhrase(lower[i:], now)
+		if n == 0 {
+			continue
+		}
+		rest := append(append([]string{}, tokens[:i]...), tokens[i+n:]...)
+		return Query{Text: strings.Join(rest, " "), Range: &r}
+	}
+	return Query{Text: strings.Join(tokens, " ")}
+}
+
+// parseDatePhrase matches a date phrase at the start of toks and returns
+// its range and the number of tokens used, or 0 if there is none.
+func parseDatePhrase(toks []string, now time.Time) (Range, int) {
+	today := startOfDay(now)
+	switch toks[0] {
+	case "today":
+		return dayRange(today, 0), 1
+	case "yesterday":
+		return dayRange(today, -1), 1
+	}
+	if wd, ok := weekdays[toks[0]]; ok {
+		back := (int(today.Weekday()) - int(wd) + 7) % 7
+		return dayRange(today, -back), 1
+	}
+
+	if len(toks) >= 2 {
+		switch toks[0] {
+		case "on":
+			if wd, ok := weekdays[toks[1]]; ok {
+				back := (int(today.Weekday()) - int(wd) + 7) % 7
+				return dayRange(today, -back), 2
+			}
+		case "this", "last":
+			offset := 0
+			if toks[0] == "last" {
+				offset = -1
+			}
+			if wd, ok := weekdays[toks[1]]; ok {
+				back := (int(today.Weekday()) - int(wd) + 7) % 7
+				if back == 0 && offset < 0 {
+					back = 7
+				}
+				return dayRange(today, -back), 2
+			}
+			if r, ok := calendarRange(strings.TrimSuffix(toks[1], "s"), today, offset); ok {
+				return r, 2
+			}
+		case "in":
+			if m, ok := months[toks[1]]; ok {
+				year := today.Year()
+				n := 2
+				if len(toks) >= 3 {
+					if y, ok := parseYear(toks[2]); ok {
+						year, n = y, 3
+					}
+				}
+				if n == 2 && m > today.Month() {
+					year-- // The most recent one
+				}
+				from := time.Date(year, m, 1, 0, 0, 0, 0, now.Location())
+				return Range{From: from, To: from.AddDate(0, 1, 0), Wall: true}, n
+			}
+			if y, ok := parseYear(toks[1]); ok {
+				from := time.Date(y, 1, 1, 0, 0, 0, 0, now.Location())
+				return Range{From: from, To: from.AddDate(1, 0, 0), Wall: true}, 2
+			}
+		}
+	}
+
+	if len(toks) >= 3 {
+		// "last 3 days", "past 2 hours": rolling, up to now.
+		if toks[0] == "last" || toks[0] == "past" {
+			if n, ok := parseCount(toks[1]); ok {
+				if from, ok := subtract(now, toks[2], n); ok {
+					return Range{From: from, To: now.Add(time.Nanosecond)}, 3
+				}
+			}
+		}
+		// "2 hours ago", "a week ago"
+		if toks[2] == "ago" {
+			if n, ok := parseCount(toks[0]); ok {
+				if r, ok := agoRange(now, today, toks[1], n); ok {
+					return r, 3
+				}
+			}
+		}
+	}
+	return Range{}, 0
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September,
+	"sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func dayRange(today time.Time, offset int) Range {
+	from := today.AddDate(0, 0, offset)
+	return Range{From: from, To: from.AddDate(0, 0, 1), Wall: true}
+}
+
+// calendarRange is the week, month or year offset from the current one.
+func calendarRange(unit string, today time.Time, offset int) (Range, bool) {
+	var from, to time.Time
+	switch unit {
+	case "week":
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		from = monday.AddDate(0, 0, 7*offset)
+		to = from.AddDate(0, 0, 7)
+	case "month":
+		from = time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, today.Location())
+		to = from.AddDate(0, 1, 0)
+	case "year":
+		from = time.Date(today.Year()+offset, 1, 1, 0, 0, 0, 0, today.Location())
+		to = from.AddDate(1, 0, 0)
+	default:
+		return Range{}, false
+	}
+	return Range{From: from, To: to, Wall: true}, true
+}
+
+// agoRange is the calendar day, week, month or year n units back, or for
+// minutes and hours the half unit either side of that moment.
+func agoRange(now, today time.Time, unit string, n int) (Range, bool) {
+	unit = strings.TrimSuffix(unit, "s")
+	var step time.Duration
+	switch unit {
+	case "min", "minute":
+		step = time.Minute
+	case "hour", "hr":
+		step = time.Hour
+	case "day":
+		return dayRange(today, -n), true
+	default:
+		return calendarRange(unit, today, -n)
+	}
+	at := now.Add(-time.Duration(n) * step)
+	return Range{From: at.Add(-step / 2), To: at.Add(step / 2)}, true
+}
+
+// subtract moves t back n units.
+func subtract(t time.Time, unit string, n int) (time.Time, bool) {
+	switch strings.TrimSuffix(unit, "s") {
+	case "min", "minute":
+		return t.Add(-time.Duration(n) * time.Minute), true
+	case "hour", "hr":
+		return t.Add(-time.Duration(n) * time.Hour), true
+	case "day":
+		return t.AddDate(0, 0, -n), true
+	case "week":
+		return t.AddDate(0, 0, -7*n), true
+	case "month":
+		return t.AddDate(0, -n, 0), true
+	case "year":
+		return t.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+func parseCount(s string) (int, bool) {
+	if s == "a" || s == "an" {
+		return 1, true
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n > 0
+}
+
+func parseYear(s string) (int, bool) {
+	y, err := strconv.Atoi(s)
+	return y, err == nil && len(s) == 4
+}
@@ -0,0 +1,148 @@
+package search
+
+import "unicode"
+
+// Scoring constants, modelled on fzf's v1 algorithm.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary    = scoreMatch / 2
+	bonusCamel       = bonusBoundary - 1
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+	bonusFirstChar   = 2 // Multiplier for the bonus of the first pattern char
+
+	// typoPenalty is subtracted when the pattern only matches with one of
+	// its characters dropped.
+	typoPenalty = scoreMatch * 2
+	minTypoLen  = 4
+)
+
+// Match scores pattern as a case-insensitive subsequence of text. Matches
+// at word boundaries and runs of consecutive characters score higher;
+// gaps cost points. If pattern is not a subsequence, Match retries with
+// each single character dropped so one typo still matches, at a penalty.
+// The second result is false when nothing matched.
+func Match(pattern, text string) (int, bool) {
+	p := []rune(toLower(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, true
+	}
+	if score, ok := matchRunes(p, t); ok {
+		return score, true
+	}
+	if len(p) < minTypoLen {
+		return 0, false
+	}
+
+	best, found := 0, false
+	dropped := make([]rune, len(p)-1)
+	for i := range p {
+		copy(dropped, p[:i])
+		copy(dropped[i:], p[i+1:])
+		if score, ok := matchRunes(dropped, t); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	return max(best-typoPenalty, 1), true
+}
+
+// matchRunes finds the shortest window of t, ending at the first place
+// the whole pattern has been seen, that contains p as a subsequence, then
+// scores it. p must be lower case.
+func matchRunes(p, t []rune) (int, bool) {
+	pi, end := 0, -1
+	for ti, r := range t {
+		if unicode.ToLower(r) == p[pi] {
+			pi++
+			if pi == len(p) {
+				end = ti
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+
+	// Walk back to the latest start that still contains the pattern.
+	pi = len(p) - 1
+	start := end
+	for ti := end; ti >= 0; ti-- {
+		if unicode.ToLower(t[ti]) == p[pi] {
+			pi--
+			if pi < 0 {
+				start = ti
+				break
+			}
+		}
+	}
+	return scoreWindow(p, t, start, end), true
+}
+
+func scoreWindow(p, t []rune, start, end int) int {
+	score, pi := 0, 0
+	inGap, consecutive := false, 0
+	firstBonus := 0
+	for ti := start; ti <= end; ti++ {
+		if pi < len(p) && unicode.ToLower(t[ti]) == p[pi] {
+			score += scoreMatch
+			bonus := charBonus(t, ti)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// A run keeps the bonus of its first character.
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pi == 0 {
+				score += bonus * bonusFirstChar
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pi++
+			continue
+		}
+		if inGap {
+			score += scoreGapExtension
+		} else {
+			score += scoreGapStart
+		}
+		inGap = true
+		consecutive = 0
+		firstBonus = 0
+	}
+	return score
+}
+
+// charBonus rewards characters that start a word: the first character,
+// one after a separator, or an upper-case letter after a lower-case one.
+func charBonus(t []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := t[i-1], t[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+func toLower(s string) string {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
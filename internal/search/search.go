@@ -0,0 +1,162 @@
+// Package search ranks screenshots against free-text queries with date
+// phrases. It is shared by Tarragon, the TUI and `orego list`.
+package search
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+// Field weights: the focused window counts most, background client
+// titles only nudge the ranking.
+const (
+	weightTitle  = 1.0
+	weightClass  = 1.0
+	weightFile   = 0.6
+	weightClient = 0.4
+)
+
+// HalfLife is how long it takes the recency boost to halve.
+const HalfLife = 7 * 24 * time.Hour
+
+// recencyWeight is the largest boost a brand new screenshot gets, as a
+// fraction of its match score.
+const recencyWeight = 0.5
+
+// Doc is the searchable text of one screenshot.
+type Doc struct {
+	Title   string
+	Class   string
+	File    string
+	Clients []string // Titles of background windows
+	Time    time.Time
+	TZ      string // Capture timezone, for date phrases
+}
+
+// DocOf builds the searchable document for sc. Clients are only included
+// if they were loaded (db.ListOptions.WithClients).
+func DocOf(sc *models.Screenshot) Doc {
+	doc := Doc{
+		Title: sc.ActiveWindow.Title,
+		Class: sc.ActiveWindow.Class,
+		File:  filepath.Base(vault.PlainName(sc.FilePath)),
+		Time:  sc.Capture.Ts,
+		TZ:    sc.Capture.Timezone,
+	}
+	for _, c := range sc.Clients {
+		if c.Title != "" && c.Title != sc.ActiveWindow.Title {
+			doc.Clients = append(doc.Clients, c.Title)
+		}
+	}
+	return doc
+}
+
+// Score rates doc against query at time now. Every whitespace separated
+// token has to match some field; the best field counts for each token.
+// The total is boosted by recency. Zero means no match. An empty query
+// matches everything, ranked by recency alone.
+func Score(doc Doc, query string, now time.Time) float64 {
+	boost := 1 + recencyWeight*decay(doc.Time, now)
+	tokens := strings.Fields(query)
+	if len(tokens) == 0 {
+		return boost
+	}
+
+	total := 0.0
+	for _, tok := range tokens {
+		best := 0.0
+		try := func(text string, weight float64) {
+			if text == "" {
+				return
+			}
+			if s, ok := Match(tok, text); ok && float64(s)*weight > best {
+				best = float64(s) * weight
+			}
+		}
+		try(doc.Title, weightTitle)
+		try(doc.Class, weightClass)
+		try(doc.File, weightFile)
+		for _, c := range doc.Clients {
+			try(c, weightClient)
+		}
+		if best <= 0 {
+			return 0
+		}
+		total += best
+	}
+	return total * boost
+}
+
+// Score rates doc like the package level Score, but only if it falls in
+// the query's date range.
+func (q Query) Score(doc Doc, now time.Time) float64 {
+	if q.Range != nil && !q.Range.Contains(doc.Time, doc.TZ) {
+		return 0
+	}
+	return Score(doc, q.Text, now)
+}
+
+// decay is 1 for a screenshot taken now and halves every HalfLife.
+func decay(t, now time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	age := now.Sub(t)
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(HalfLife))
+}
+
+// Hit is one ranked document, identified by its index in the input.
+type Hit struct {
+	Index int
+	Score float64
+}
+
+// Rank scores docs against query, date phrases included, and returns the
+// matches, best first. Equal scores keep their input order.
+func Rank(docs []Doc, query string, now time.Time) []Hit {
+	q := ParseQuery(query, now)
+	hits := make([]Hit, 0, len(docs))
+	for i, doc := range docs {
+		if s := q.Score(doc, now); s > 0 {
+			hits = append(hits, Hit{Index: i, Score: s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	return hits
+}
+
+// Screenshots ranks screenshots against query, best first.
+func Screenshots(screenshots []models.Screenshot, query string, now time.Time) []models.Screenshot {
+	docs := make([]Doc, len(screenshots))
+	for i := range screenshots {
+		docs[i] = DocOf(&screenshots[i])
+	}
+	hits := Rank(docs, query, now)
+	ranked := make([]models.Screenshot, len(hits))
+	for i, h := range hits {
+		ranked[i] = screenshots[h.Index]
+	}
+	return ranked
+}
+
+// InRange keeps the screenshots taken in r.
+func InRange(screenshots []models.Screenshot, r Range) []models.Screenshot {
+	kept := make([]models.Screenshot, 0, len(screenshots))
+	for _, sc := range screenshots {
+		if r.Contains(sc.Capture.Ts, sc.Capture.Timezone) {
+			kept = append(kept, sc)
+		}
+	}
+	return kept
+}
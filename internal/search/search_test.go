@@ -0,0 +1,183 @@
+package search
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		ok      bool
+	}{
+		{"empty pattern", "", "firefox", true},
+		{"exact", "firefox", "firefox", true},
+		{"subsequence", "frfx", "firefox", true},
+		{"case insensitive", "FiReFoX", "Firefox", true},
+		{"across words", "ghpr", "GitHub Pull Request", true},
+		{"one typo", "fierfox", "firefox", true},
+		{"swapped letters", "frieofx", "firefox", false},
+		{"short miss", "xyz", "firefox", false},
+		{"short typo", "fxr", "firefox", false},
+		{"miss", "chromium", "firefox", false},
+		{"pattern longer than text", "firefoxes", "fox", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := Match(tt.pattern, tt.text)
+			if ok != tt.ok {
+				t.Fatalf("Match(%q, %q) ok = %v, want %v (score %d)", tt.pattern, tt.text, ok, tt.ok, score)
+			}
+			if ok && tt.pattern != "" && score <= 0 {
+				t.Errorf("Match(%q, %q) = %d, want a positive score", tt.pattern, tt.text, score)
+			}
+		})
+	}
+}
+
+// TestMatchOrdering checks that each better text outscores the worse one
+// for the same pattern.
+func TestMatchOrdering(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		better, worse string
+	}{
+		{"consecutive beats gap", "abc", "xabcx", "xaxbxcx"},
+		{"short gap beats long gap", "ab", "axb", "axxxxxb"},
+		{"word start beats middle", "hub", "git hub", "githubx"},
+		{"camel case boundary", "pr", "PullRequest", "approve"},
+		{"exact beats typo", "firefox", "firefox", "firfox"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better, ok := Match(tt.pattern, tt.better)
+			if !ok {
+				t.Fatalf("Match(%q, %q) did not match", tt.pattern, tt.better)
+			}
+			worse, ok := Match(tt.pattern, tt.worse)
+			if !ok {
+				t.Fatalf("Match(%q, %q) did not match", tt.pattern, tt.worse)
+			}
+			if better <= worse {
+				t.Errorf("Match(%q, %q) = %d, want more than %q = %d", tt.pattern, tt.better, better, tt.worse, worse)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	doc := Doc{
+		Title:   "Pull requests · GitHub",
+		Class:   "firefox",
+		File:    "2026-10-18_120000.png",
+		Clients: []string{"nvim main.go", "Slack | general"},
+	}
+	tests := []struct {
+		name  string
+		query string
+		match bool
+	}{
+		{"empty query", "", true},
+		{"title", "github", true},
+		{"class", "firefox", true},
+		{"file", "20261018", true},
+		{"client title", "slack", true},
+		{"title and class", "github firefox", true},
+		{"title and client", "pull nvim", true},
+		{"one token misses", "github chromium", false},
+		{"miss", "thunderbird", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(doc, tt.query, time.Time{}) > 0; got != tt.match {
+				t.Errorf("Score(%q) matched = %v, want %v", tt.query, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestScoreWeights(t *testing.T) {
+	focused := Doc{Title: "Slack | general", Class: "slack"}
+	background := Doc{Title: "nvim", Class: "kitty", Clients: []string{"Slack | general"}}
+	if f, b := Score(focused, "slack", time.Time{}), Score(background, "slack", time.Time{}); f <= b {
+		t.Errorf("focused window scored %v, want more than background client %v", f, b)
+	}
+
+	one := Score(Doc{Title: "GitHub", Class: "firefox"}, "github", time.Time{})
+	two := Score(Doc{Title: "GitHub", Class: "firefox"}, "github firefox", time.Time{})
+	if two <= one {
+		t.Errorf("two matching tokens scored %v, want more than one token %v", two, one)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    time.Time
+		want float64
+	}{
+		{"now", now, 1},
+		{"future", now.Add(time.Hour), 1},
+		{"one half-life", now.Add(-HalfLife), 0.5},
+		{"two half-lives", now.Add(-2 * HalfLife), 0.25},
+		{"unknown time", time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decay(tt.t, now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("decay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	recent := Score(Doc{Title: "GitHub", Time: now.Add(-time.Hour)}, "github", now)
+	old := Score(Doc{Title: "GitHub", Time: now.Add(-30 * 24 * time.Hour)}, "github", now)
+	if recent <= old {
+		t.Errorf("recent screenshot scored %v, want more than old one %v", recent, old)
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	docs := []Doc{
+		{Title: "Inbox - Thunderbird", Class: "thunderbird", Time: now},
+		{Title: "f-i-r-e-f-o-x notes", Class: "kitty", Time: now},
+		{Title: "Mozilla Firefox", Class: "firefox", Time: now},
+		{Title: "Mozilla Firefox", Class: "firefox", Time: now},
+		{Title: "Mozilla Firefox", Class: "firefox", Time: now.AddDate(0, 0, -3)},
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"best first, ties in input order", "firefox", []int{2, 3, 4, 1}},
+		{"empty query by recency", "", []int{0, 1, 2, 3, 4}},
+		{"date phrase filters", "firefox today", []int{2, 3, 1}},
+		{"only date phrase", "3 days ago", []int{4}},
+		{"no match", "chromium", []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := Rank(docs, tt.query, now)
+			got := make([]int, len(hits))
+			for i, h := range hits {
+				got[i] = h.Index
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Rank(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := 1; i < len(hits); i++ {
+				if hits[i].Score > hits[i-1].Score {
+					t.Errorf("Rank(%q) not sorted: %v", tt.query, hits)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"orego/internal/config"
+)
+
+// commandUploader runs an external program such as scp or rsync. The
+// link is rendered from PublicURL, or taken from the last line the
+// command prints.
+type commandUploader struct {
+	target config.ShareTarget
+}
+
+func (u commandUploader) Upload(ctx context.Context, f File) (string, error) {
+	data := newTemplateData(f, f.Name)
+
+	args := make([]string, 0, len(u.target.Args))
+	for _, arg := range u.target.Args {
+		rendered, err := render(arg, data)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, rendered)
+	}
+
+	cmd := exec.CommandContext(ctx, u.target.Cmd, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", u.target.Cmd, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if u.target.PublicURL != "" {
+		return render(u.target.PublicURL, data)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	link := strings.TrimSpace(lines[len(lines)-1])
+	if link == "" {
+		return "", fmt.Errorf("%s printed no link, set public_url", u.target.Cmd)
+	}
+	return link, nil
+}
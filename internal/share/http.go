@@ -0,0 +1,128 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+
+	"orego/internal/config"
+)
+
+// httpUploader POSTs the file as multipart form data. The link is read
+// from the JSON response at URLPath, rendered from PublicURL, or taken
+// from the plain response body.
+type httpUploader struct {
+	target config.ShareTarget
+}
+
+func (u httpUploader) Upload(ctx context.Context, f File) (string, error) {
+	data := newTemplateData(f, f.Name)
+
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+
+	field := u.target.Field
+	if field == "" {
+		field = "file"
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, f.Name))
+	h.Set("Content-Type", f.MimeType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	method := u.target.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	url, err := render(u.target.URL, data)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	for name, value := range u.target.Headers {
+		rendered, err := render(value, data)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set(name, rendered)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("upload failed: %s: %s", resp.Status, snippet(respBody))
+	}
+
+	if u.target.URLPath != "" {
+		return jsonLink(respBody, u.target.URLPath)
+	}
+	if u.target.PublicURL != "" {
+		return render(u.target.PublicURL, data)
+	}
+	link := strings.TrimSpace(string(respBody))
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return "", fmt.Errorf("response is not a link, set url_path or public_url: %s", snippet(respBody))
+	}
+	return link, nil
+}
+
+// jsonLink follows a dotted path such as "data.link" or "files.0.url"
+// through a JSON document and returns the string found there.
+func jsonLink(body []byte, path string) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "", fmt.Errorf("response is not JSON: %s", snippet(body))
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("url_path %q: no element %q in response", path, key)
+			}
+			v = node[i]
+		default:
+			return "", fmt.Errorf("url_path %q: no field %q in response", path, key)
+		}
+	}
+	link, ok := v.(string)
+	if !ok || link == "" {
+		return "", fmt.Errorf("url_path %q does not point to a string in the response", path)
+	}
+	return link, nil
+}
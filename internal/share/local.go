@@ -0,0 +1,61 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"orego/internal/config"
+)
+
+// localUploader copies the file into a directory. Without PublicURL the
+// link is a file:// URL, which makes it a stand-in for real targets or a
+// way to share through a synced folder.
+type localUploader struct {
+	target config.ShareTarget
+}
+
+func (u localUploader) Upload(ctx context.Context, f File) (string, error) {
+	dir := u.target.Dir
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home dir: %w", err)
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	dest := filepath.Join(dir, f.Name)
+	if err := copyFile(f.Path, dest); err != nil {
+		return "", err
+	}
+
+	data := newTemplateData(f, f.Name)
+	return publicURL(u.target, data, (&url.URL{Scheme: "file", Path: dest}).String())
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return fmt.Errorf("failed to copy to %s: %w", dst, err)
+	}
+	return out.Close()
+}
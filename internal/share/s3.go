@@ -0,0 +1,155 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"orego/internal/config"
+)
+
+// s3Uploader PUTs the file into an S3-compatible bucket using path-style
+// addressing and AWS Signature Version 4.
+type s3Uploader struct {
+	target config.ShareTarget
+}
+
+func (u s3Uploader) Upload(ctx context.Context, f File) (string, error) {
+	t := u.target
+	key := strings.TrimPrefix(t.Prefix+f.Name, "/")
+	data := newTemplateData(f, key)
+
+	accessKey, secretKey := t.AccessKey, t.SecretKey
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if secretKey == "" {
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("s3 target needs access_key and secret_key (or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	region := t.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(t.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return "", fmt.Errorf("invalid s3 endpoint %q", t.Endpoint)
+	}
+	objectURL := *endpoint
+	objectURL.Path = endpoint.Path + "/" + t.Bucket + "/" + key
+	objectURL.RawPath = escapePath(objectURL.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", f.MimeType)
+	if t.ACL != "" {
+		req.Header.Set("X-Amz-Acl", t.ACL)
+	}
+	signV4(req, content, accessKey, secretKey, region, time.Now().UTC())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("upload failed: %s: %s", resp.Status, snippet(body))
+	}
+
+	return publicURL(t, data, objectURL.String())
+}
+
+// signV4 adds the headers and Authorization of an AWS Signature Version 4
+// for the s3 service.
+func signV4(req *http.Request, payload []byte, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// escapePath URI-encodes every path segment the way SigV4 expects:
+// everything but unreserved characters is percent-encoded.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
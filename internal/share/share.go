@@ -0,0 +1,98 @@
+// Package share uploads screenshots to configurable targets and returns
+// the link to the uploaded file.
+package share
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"orego/internal/config"
+)
+
+// File is a screenshot prepared for upload. Path is readable by external
+// programs, i.e. already decrypted.
+type File struct {
+	Path     string
+	Name     string
+	MimeType string
+}
+
+// Uploader sends a file somewhere and returns a link to it.
+type Uploader interface {
+	Upload(ctx context.Context, f File) (string, error)
+}
+
+// New returns the uploader for a configured target.
+func New(t config.ShareTarget) (Uploader, error) {
+	switch t.Type {
+	case "http":
+		if t.URL == "" {
+			return nil, fmt.Errorf("http target needs a url")
+		}
+		return httpUploader{t}, nil
+	case "s3":
+		if t.Endpoint == "" || t.Bucket == "" {
+			return nil, fmt.Errorf("s3 target needs an endpoint and a bucket")
+		}
+		return s3Uploader{t}, nil
+	case "command":
+		if t.Cmd == "" {
+			return nil, fmt.Errorf("command target needs a cmd")
+		}
+		return commandUploader{t}, nil
+	case "local":
+		if t.Dir == "" {
+			return nil, fmt.Errorf("local target needs a dir")
+		}
+		return localUploader{t}, nil
+	case "":
+		return nil, fmt.Errorf("target has no type")
+	default:
+		return nil, fmt.Errorf("unknown target type %q (supported: http, s3, command, local)", t.Type)
+	}
+}
+
+// templateData is what header, argument and public_url templates see.
+type templateData struct {
+	Path     string
+	Name     string
+	Key      string
+	MimeType string
+}
+
+func newTemplateData(f File, key string) templateData {
+	return templateData{Path: f.Path, Name: f.Name, Key: key, MimeType: f.MimeType}
+}
+
+func render(text string, data templateData) (string, error) {
+	tmpl, err := template.New("share").Funcs(config.TemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// publicURL renders the target's public_url, or returns fallback if it is
+// not set.
+func publicURL(t config.ShareTarget, data templateData, fallback string) (string, error) {
+	if t.PublicURL == "" {
+		return fallback, nil
+	}
+	return render(t.PublicURL, data)
+}
+
+// snippet shortens a response body for error messages.
+func snippet(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+	return s
+}
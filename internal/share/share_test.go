@@ -0,0 +1,285 @@
+package share
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"orego/internal/config"
+)
+
+var testImage = []byte("\x89PNG\r\n\x1a\nfake image")
+
+// testFile writes a small image to a temp dir and returns it for upload.
+func testFile(t *testing.T) File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(path, testImage, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return File{Path: path, Name: "shot 1.png", MimeType: "image/png"}
+}
+
+func upload(t *testing.T, target config.ShareTarget, f File) (string, error) {
+	t.Helper()
+	u, err := New(target)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return u.Upload(context.Background(), f)
+}
+
+func TestHTTPUploader(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  config.ShareTarget
+		field   string
+		reply   string
+		status  int
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "plain link, default field",
+			reply:  "https://img.example/abc\n",
+			field:  "file",
+			status: http.StatusOK,
+			want:   "https://img.example/abc",
+		},
+		{
+			name:   "url_path into JSON",
+			target: config.ShareTarget{Field: "image", URLPath: "data.files.0.url"},
+			field:  "image",
+			reply:  `{"data":{"files":[{"url":"https://img.example/xyz"}]}}`,
+			status: http.StatusCreated,
+			want:   "https://img.example/xyz",
+		},
+		{
+			name:   "public_url",
+			target: config.ShareTarget{PublicURL: "https://cdn.example/{{.Name}}"},
+			field:  "file",
+			reply:  "stored",
+			status: http.StatusOK,
+			want:   "https://cdn.example/shot 1.png",
+		},
+		{
+			name:    "url_path missing",
+			target:  config.ShareTarget{URLPath: "data.link"},
+			field:   "file",
+			reply:   `{"data":{}}`,
+			status:  http.StatusOK,
+			wantErr: `url_path "data.link"`,
+		},
+		{
+			name:    "error status",
+			field:   "file",
+			reply:   "quota exceeded",
+			status:  http.StatusForbidden,
+			wantErr: "403 Forbidden: quota exceeded",
+		},
+		{
+			name:    "body is not a link",
+			field:   "file",
+			reply:   "<html>ok</html>",
+			status:  http.StatusOK,
+			wantErr: "response is not a link",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer s3cret" {
+					t.Errorf("Authorization = %q, want templated header", got)
+				}
+				if got := r.Header.Get("X-File"); got != "shot 1.png image/png" {
+					t.Errorf("X-File = %q, want templated header", got)
+				}
+				file, header, err := r.FormFile(tt.field)
+				if err != nil {
+					t.Errorf("no form field %q: %v", tt.field, err)
+				} else {
+					body, _ := io.ReadAll(file)
+					if string(body) != string(testImage) {
+						t.Errorf("uploaded %q, want the file content", body)
+					}
+					if header.Filename != "shot 1.png" {
+						t.Errorf("filename = %q, want shot 1.png", header.Filename)
+					}
+					if ct := header.Header.Get("Content-Type"); ct != "image/png" {
+						t.Errorf("part Content-Type = %q, want image/png", ct)
+					}
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.reply)
+			}))
+			defer srv.Close()
+
+			t.Setenv("SHARE_TOKEN", "s3cret")
+			target := tt.target
+			target.Type = "http"
+			target.URL = srv.URL + "/upload"
+			target.Headers = map[string]string{
+				"Authorization": `Bearer {{env "SHARE_TOKEN"}}`,
+				"X-File":        "{{.Name}} {{.MimeType}}",
+			}
+
+			link, err := upload(t, target, testFile(t))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if link != tt.want {
+				t.Errorf("link = %q, want %q", link, tt.want)
+			}
+		})
+	}
+}
+
+var authPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/(\d{8})/eu-central-1/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+func TestS3Uploader(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	link, err := upload(t, config.ShareTarget{
+		Type:      "s3",
+		Endpoint:  srv.URL,
+		Bucket:    "shots",
+		Prefix:    "2026/",
+		Region:    "eu-central-1",
+		ACL:       "public-read",
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "secret",
+	}, testFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", got.Method)
+	}
+	if got.URL.EscapedPath() != "/shots/2026/shot%201.png" {
+		t.Errorf("path = %s, want /shots/2026/shot%%201.png", got.URL.EscapedPath())
+	}
+	if want := srv.URL + "/shots/2026/shot%201.png"; link != want {
+		t.Errorf("link = %q, want %q", link, want)
+	}
+	if string(body) != string(testImage) {
+		t.Errorf("body = %q, want the file content", body)
+	}
+	if ct := got.Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", ct)
+	}
+	if acl := got.Header.Get("X-Amz-Acl"); acl != "public-read" {
+		t.Errorf("X-Amz-Acl = %q, want public-read", acl)
+	}
+
+	sum := sha256.Sum256(testImage)
+	if h := got.Header.Get("X-Amz-Content-Sha256"); h != hex.EncodeToString(sum[:]) {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want the payload hash", h)
+	}
+	date, err := time.Parse("20060102T150405Z", got.Header.Get("X-Amz-Date"))
+	if err != nil {
+		t.Fatalf("X-Amz-Date: %v", err)
+	}
+
+	m := authPattern.FindStringSubmatch(got.Header.Get("Authorization"))
+	if m == nil {
+		t.Fatalf("Authorization = %q, not a SigV4 header", got.Header.Get("Authorization"))
+	}
+	if m[1] != date.Format("20060102") {
+		t.Errorf("credential scope date %s does not match X-Amz-Date %s", m[1], date.Format("20060102"))
+	}
+	for _, h := range []string{"content-type", "host", "x-amz-acl", "x-amz-content-sha256", "x-amz-date"} {
+		if !strings.Contains(";"+m[2]+";", ";"+h+";") {
+			t.Errorf("SignedHeaders = %s, missing %s", m[2], h)
+		}
+	}
+}
+
+func TestS3UploaderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+	}))
+	defer srv.Close()
+
+	_, err := upload(t, config.ShareTarget{
+		Type: "s3", Endpoint: srv.URL, Bucket: "shots", AccessKey: "a", SecretKey: "b",
+	}, testFile(t))
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("err = %v, want the S3 error", err)
+	}
+}
+
+// TestSignV4 checks that the signature is deterministic and covers the
+// payload.
+func TestSignV4(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	sign := func(payload string) string {
+		req := httptest.NewRequest(http.MethodPut, "https://s3.example/bucket/key.png", nil)
+		signV4(req, []byte(payload), "AKID", "secret", "us-east-1", now)
+		return req.Header.Get("Authorization")
+	}
+	if a, b := sign("one"), sign("one"); a != b {
+		t.Errorf("signatures differ for the same request:\n%s\n%s", a, b)
+	}
+	if a, b := sign("one"), sign("two"); a == b {
+		t.Errorf("signature does not depend on the payload")
+	}
+	if a := sign("one"); !strings.Contains(a, "Credential=AKID/20261018/us-east-1/s3/aws4_request") {
+		t.Errorf("Authorization = %q, wrong credential scope", a)
+	}
+}
+
+func TestLocalUploader(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicURL string
+		want      func(dir string) string
+	}{
+		{"file URL", "", func(dir string) string { return "file://" + filepath.Join(dir, "shot%201.png") }},
+		{"public_url", "https://files.example/{{.Name}}", func(string) string { return "https://files.example/shot 1.png" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "shared", "nested")
+			link, err := upload(t, config.ShareTarget{Type: "local", Dir: dir, PublicURL: tt.publicURL}, testFile(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want(dir); link != want {
+				t.Errorf("link = %q, want %q", link, want)
+			}
+			copied, err := os.ReadFile(filepath.Join(dir, "shot 1.png"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(copied) != string(testImage) {
+				t.Errorf("copied %q, want the file content", copied)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package stats aggregates the screenshot database into usage reports for
+// `orego stats` and the TUI.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"orego/internal/search"
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+// Count is one row of a ranking or time series.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size,omitempty"` // Bytes, before vault encryption
+}
+
+// Report summarizes the screenshots taken since Since. Days and Weeks
+// cover the whole period, empty ones included, oldest first. Rankings
+// are sorted by count, largest first; Storage by size.
+type Report struct {
+	Since      time.Time `json:"since,omitzero"`
+	Total      int       `json:"total"`
+	Size       int64     `json:"size"`
+	Days       []Count   `json:"per_day"`
+	Weeks      []Count   `json:"per_week"`
+	Hours      [24]int   `json:"per_hour"`
+	Apps       []Count   `json:"top_apps"`
+	Workspaces []Count   `json:"top_workspaces"`
+	Monitors   []Count   `json:"top_monitors"`
+	Storage    []Count   `json:"storage_by_app"`
+	Background []Count   `json:"background_apps"` // Apps open but not focused
+
+	// BackgroundLocked is set when client lists were sealed by the locked
+	// vault and left out of Background.
+	BackgroundLocked bool `json:"background_locked,omitempty"`
+}
+
+// Compute builds the report for the screenshots taken at or after since,
+// up to now. A zero since starts at the oldest screenshot. Days and hours
+// are on the wall clock of each capture. Background apps are only counted
+// if clients were loaded (db.ListOptions.WithClients) and are readable.
+func Compute(screenshots []models.Screenshot, since, now time.Time) Report {
+	r := Report{Since: since}
+
+	days := map[string]int{}
+	apps := map[string]*Count{}
+	workspaces := map[string]int{}
+	monitors := map[string]int{}
+	background := map[string]int{}
+	first := "" // Earliest day with a capture
+
+	for _, sc := range screenshots {
+		ts := sc.Capture.Ts
+		if !since.IsZero() && ts.Before(since) {
+			continue
+		}
+		wall := search.CaptureTime(ts, sc.Capture.Timezone)
+		day := wall.Format(time.DateOnly)
+		if first == "" || day < first {
+			first = day
+		}
+
+		r.Total++
+		r.Size += sc.Size
+		days[day]++
+		r.Hours[wall.Hour()]++
+
+		app := label(sc.ActiveWindow.Class)
+		if apps[app] == nil {
+			apps[app] = &Count{Name: app}
+		}
+		apps[app].Count++
+		apps[app].Size += sc.Size
+
+		workspaces[label(sc.Workspace.Name)]++
+		monitors[label(sc.Workspace.Monitor)]++
+
+		seen := map[string]bool{}
+		for _, c := range sc.Clients {
+			if c.Class == vault.LockedPlaceholder {
+				r.BackgroundLocked = true
+				continue
+			}
+			if c.Class == "" || c.Class == sc.ActiveWindow.Class || seen[c.Class] {
+				continue
+			}
+			seen[c.Class] = true
+			background[c.Class]++
+		}
+	}
+
+	if r.Total == 0 {
+		return r
+	}
+	start := since
+	if start.IsZero() {
+		start, _ = time.ParseInLocation(time.DateOnly, first, now.Location())
+	}
+
+	// Dates are compared as strings, so the series follows the calendar
+	// even though captures may carry different offsets.
+	for d := startOfDay(start); !d.After(now); d = d.AddDate(0, 0, 1) {
+		key := d.Format(time.DateOnly)
+		r.Days = append(r.Days, Count{Name: key, Count: days[key]})
+
+		year, week := d.ISOWeek()
+		name := fmt.Sprintf("%d-W%02d", year, week)
+		if n := len(r.Weeks); n == 0 || r.Weeks[n-1].Name != name {
+			r.Weeks = append(r.Weeks, Count{Name: name})
+		}
+		r.Weeks[len(r.Weeks)-1].Count += days[key]
+	}
+
+	for _, c := range apps {
+		r.Apps = append(r.Apps, *c)
+	}
+	r.Storage = append([]Count(nil), r.Apps...)
+	sortCounts(r.Apps)
+	sort.SliceStable(r.Storage, func(i, j int) bool {
+		if r.Storage[i].Size != r.Storage[j].Size {
+			return r.Storage[i].Size > r.Storage[j].Size
+		}
+		return r.Storage[i].Name < r.Storage[j].Name
+	})
+
+	r.Workspaces = ranking(workspaces)
+	r.Monitors = ranking(monitors)
+	r.Background = ranking(background)
+	return r
+}
+
+// Top returns at most n entries of counts.
+func Top(counts []Count, n int) []Count {
+	return counts[:min(len(counts), n)]
+}
+
+// Values returns the counts of a series, for Sparkline.
+func Values(counts []Count) []int {
+	values := make([]int, len(counts))
+	for i, c := range counts {
+		values[i] = c.Count
+	}
+	return values
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of block characters scaled to the
+// largest value. Zero is drawn as a space.
+func Sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		if v <= 0 || peak == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparks[(v*len(sparks)-1)/peak])
+	}
+	return b.String()
+}
+
+func ranking(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for name, n := range m {
+		counts = append(counts, Count{Name: name, Count: n})
+	}
+	sortCounts(counts)
+	return counts
+}
+
+func sortCounts(counts []Count) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+}
+
+func label(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
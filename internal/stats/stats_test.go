@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"orego/internal/vault"
+	"orego/pkg/models"
+)
+
+func shot(class string, ts time.Time, tz string, size int64, clients ...string) models.Screenshot {
+	sc := models.Screenshot{
+		Capture:      models.CaptureMetadata{Ts: ts, Timezone: tz},
+		ActiveWindow: models.ActiveWindow{Class: class},
+		Workspace:    models.Workspace{Name: "1", Monitor: "DP-1"},
+		Size:         size,
+	}
+	for _, c := range clients {
+		sc.Clients = append(sc.Clients, models.Client{Class: c})
+	}
+	return sc
+}
+
+func TestComputeWallClock(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Prague"); err != nil {
+		t.Skip("no timezone data")
+	}
+	pacific := time.FixedZone("PDT", -7*60*60)
+	screenshots := []models.Screenshot{
+		// Stored with its offset: 05:00 UTC on the 17th.
+		shot("firefox", time.Date(2026, 10, 16, 22, 0, 0, 0, pacific), "America/Los_Angeles", 100),
+		// Stored in UTC: 01:30 on the 17th in Prague.
+		shot("kitty", time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC), "Europe/Prague", 300),
+	}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	r := Compute(screenshots, time.Time{}, now)
+
+	wantDays := []Count{{Name: "2026-10-16", Count: 1}, {Name: "2026-10-17", Count: 1}, {Name: "2026-10-18"}}
+	if !slices.Equal(r.Days, wantDays) {
+		t.Errorf("Days = %v, want %v", r.Days, wantDays)
+	}
+	for hour, want := range map[int]int{22: 1, 1: 1, 5: 0, 23: 0} {
+		if r.Hours[hour] != want {
+			t.Errorf("Hours[%d] = %d, want %d", hour, r.Hours[hour], want)
+		}
+	}
+}
+
+func TestComputeSince(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.UTC) }
+	screenshots := []models.Screenshot{
+		shot("firefox", day(1, 9), "", 1000),
+		shot("firefox", day(12, 9), "", 100, "kitty", "kitty", "firefox", ""),
+		shot("firefox", day(14, 10), "", 50, "kitty"),
+		shot("kitty", day(14, 11), "", 400, "slack"),
+	}
+
+	r := Compute(screenshots, day(12, 0), day(14, 12))
+
+	if r.Total != 3 || r.Size != 550 {
+		t.Errorf("Total, Size = %d, %d, want 3, 550", r.Total, r.Size)
+	}
+	wantDays := []Count{{Name: "2026-10-12", Count: 1}, {Name: "2026-10-13"}, {Name: "2026-10-14", Count: 2}}
+	if !slices.Equal(r.Days, wantDays) {
+		t.Errorf("Days = %v, want %v", r.Days, wantDays)
+	}
+	wantWeeks := []Count{{Name: "2026-W42", Count: 3}}
+	if !slices.Equal(r.Weeks, wantWeeks) {
+		t.Errorf("Weeks = %v, want %v", r.Weeks, wantWeeks)
+	}
+
+	wantApps := []Count{{Name: "firefox", Count: 2, Size: 150}, {Name: "kitty", Count: 1, Size: 400}}
+	if !slices.Equal(r.Apps, wantApps) {
+		t.Errorf("Apps = %v, want %v", r.Apps, wantApps)
+	}
+	wantStorage := []Count{wantApps[1], wantApps[0]}
+	if !slices.Equal(r.Storage, wantStorage) {
+		t.Errorf("Storage = %v, want %v", r.Storage, wantStorage)
+	}
+	wantBackground := []Count{{Name: "kitty", Count: 2}, {Name: "slack", Count: 1}}
+	if !slices.Equal(r.Background, wantBackground) {
+		t.Errorf("Background = %v, want %v", r.Background, wantBackground)
+	}
+	if want := []Count{{Name: "1", Count: 3}}; !slices.Equal(r.Workspaces, want) {
+		t.Errorf("Workspaces = %v, want %v", r.Workspaces, want)
+	}
+}
+
+func TestComputeLockedClients(t *testing.T) {
+	ts := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	screenshots := []models.Screenshot{
+		shot("firefox", ts, "", 10, vault.LockedPlaceholder, vault.LockedPlaceholder),
+		shot("kitty", ts, "", 10, "slack"),
+	}
+
+	r := Compute(screenshots, time.Time{}, ts)
+
+	if want := []Count{{Name: "slack", Count: 1}}; !slices.Equal(r.Background, want) {
+		t.Errorf("Background = %v, want %v", r.Background, want)
+	}
+	if !r.BackgroundLocked {
+		t.Error("BackgroundLocked = false, want true")
+	}
+}
+
+func TestComputeEmpty(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	r := Compute(nil, time.Time{}, now)
+	if r.Total != 0 || r.Days != nil || r.Apps != nil {
+		t.Errorf("Compute(nil) = %+v, want an empty report", r)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   string
+	}{
+		{nil, ""},
+		{[]int{0, 0}, "  "},
+		{[]int{5}, "█"},
+		{[]int{1, 2, 4, 8}, "▁▂▄█"},
+		{[]int{0, 3, -1}, " █ "},
+		{[]int{1, 100}, "▁█"},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.values); got != tt.want {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
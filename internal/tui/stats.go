@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"orego/internal/retention"
+	"orego/internal/stats"
+)
+
+var (
+	statsHeading = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	statsSpark   = lipgloss.NewStyle().Foreground(lipgloss.Color("229"))
+	statsFaint   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// statsView renders the stats tab for the screenshots in the current
+// album: sparklines of recent activity and the top rankings.
+func (m model) statsView() string {
+	r := stats.Compute(m.all, time.Time{}, time.Now())
+	if r.Total == 0 {
+		return "No screenshots yet."
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	const labelW = 16
+	sparkW := max(10, width-labelW-2)
+
+	var b strings.Builder
+	title := fmt.Sprintf("%d screenshots, %s", r.Total, retention.FormatSize(r.Size))
+	if m.album != "" {
+		title += " in " + m.album
+	}
+	b.WriteString(statsHeading.Render(title) + "\n\n")
+
+	spark := func(label string, counts []stats.Count) {
+		counts = counts[max(0, len(counts)-sparkW):]
+		fmt.Fprintf(&b, "%-*s%s\n", labelW, label, statsSpark.Render(stats.Sparkline(stats.Values(counts))))
+		fmt.Fprintf(&b, "%-*s%s\n", labelW, "", statsFaint.Render(counts[0].Name+" .. "+counts[len(counts)-1].Name))
+	}
+	spark("Per day", r.Days)
+	spark("Per week", r.Weeks)
+	fmt.Fprintf(&b, "%-*s%s\n", labelW, "Busiest hours", statsSpark.Render(stats.Sparkline(r.Hours[:])))
+	fmt.Fprintf(&b, "%-*s%s\n", labelW, "", statsFaint.Render("0     6     12    18   23"))
+
+	background := statsRanking("Background", r.Background, false)
+	if r.BackgroundLocked {
+		background += "\n" + statsFaint.Render("vault locked")
+	}
+	columns := []string{
+		statsRanking("Top apps", r.Apps, false),
+		statsRanking("Workspaces", r.Workspaces, false),
+		statsRanking("Monitors", r.Monitors, false),
+		statsRanking("Storage", r.Storage, true),
+		background,
+	}
+	b.WriteString("\n")
+
+	// Lay the rankings out side by side, wrapping to the terminal width.
+	var row []string
+	rowW := 0
+	for _, col := range columns {
+		w := lipgloss.Width(col) + 3
+		if len(row) > 0 && rowW+w > width {
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, row...) + "\n\n")
+			row, rowW = nil, 0
+		}
+		row = append(row, lipgloss.NewStyle().PaddingRight(3).Render(col))
+		rowW += w
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	return b.String()
+}
+
+func statsRanking(title string, counts []stats.Count, size bool) string {
+	var b strings.Builder
+	b.WriteString(statsHeading.Render(title))
+	if len(counts) == 0 {
+		b.WriteString("\n" + statsFaint.Render("none"))
+	}
+	for _, c := range stats.Top(counts, 5) {
+		value := fmt.Sprint(c.Count)
+		if size {
+			value = retention.FormatSize(c.Size)
+		}
+		fmt.Fprintf(&b, "\n%-20.20s %8s", c.Name, value)
+	}
+	return b.String()
+}
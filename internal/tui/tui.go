@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -14,22 +16,31 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	lipglossv2 "github.com/charmbracelet/lipgloss/v2"
 
+	"orego/internal/actions"
+	"orego/internal/config"
 	"orego/internal/db"
+	"orego/internal/search"
 	"orego/pkg/models"
 )
 
-func RenderTable(store *db.Store) error {
+// RenderTable runs the interactive list. A non-empty album limits it to
+// that album; the filter can be changed with the album key.
+func RenderTable(store *db.Store, cfg config.Config, album string) error {
 	// Fetch initial data
-	entries, err := store.ListScreenshots(0, "", "")
+	entries, err := store.FindScreenshots(db.ListOptions{Album: album, WithClients: true})
 	if err != nil {
 		return err
 	}
 
 	m := model{
 		store:     store,
+		cfg:       cfg,
+		album:     album,
+		all:       entries,
 		entries:   entries,
 		showIdx:   -1,
 		deleteIdx: -1,
+		marked:    make(map[int64]bool),
 		keys:      newKeyMap(),
 		help:      help.New(),
 	}
@@ -43,8 +54,11 @@ func RenderTable(store *db.Store) error {
 
 type model struct {
 	store     *db.Store
+	cfg       config.Config
+	album     string
 	table     table.Model
-	entries   []models.Screenshot
+	all       []models.Screenshot // Everything in the album
+	entries   []models.Screenshot // What the search leaves of all, ranked
 	showIdx   int
 	deleteIdx int
 	width     int
@@ -53,6 +67,20 @@ type model struct {
 	keys      keyMap
 	help      help.Model
 	showHelp  bool
+
+	// Multi-select state: explicitly marked IDs plus an optional visual
+	// range anchored at visualFrom and extending to the cursor.
+	marked     map[int64]bool
+	visual     bool
+	visualFrom int
+
+	// Fuzzy search: query filters and ranks all into entries; searching
+	// is set while the query is being typed.
+	query     string
+	searching bool
+
+	// showStats replaces the table with the stats tab.
+	showStats bool
 }
 
 type keyMap struct {
@@ -63,6 +91,12 @@ type keyMap struct {
 	OpenFolder key.Binding
 	CopyFolder key.Binding
 	Delete     key.Binding
+	Edit       key.Binding
+	Mark       key.Binding
+	Visual     key.Binding
+	Album      key.Binding
+	Search     key.Binding
+	Stats      key.Binding
 	Help       key.Binding
 	Quit       key.Binding
 }
@@ -97,6 +131,30 @@ func newKeyMap() keyMap {
 			key.WithKeys("d"),
 			key.WithHelp("d", "delete"),
 		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark"),
+		),
+		Visual: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "visual range"),
+		),
+		Album: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "cycle album filter"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		Stats: key.NewBinding(
+			key.WithKeys("tab", "s"),
+			key.WithHelp("tab/s", "stats"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -115,14 +173,15 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Open, k.CopyImage},
-		{k.OpenFolder, k.CopyFolder, k.Delete},
-		{k.Help, k.Quit},
+		{k.OpenFolder, k.CopyFolder, k.Delete, k.Edit},
+		{k.Mark, k.Visual, k.Album, k.Search},
+		{k.Stats, k.Help, k.Quit},
 	}
 }
 
 func (m *model) initTable() {
 	cols := []table.Column{
-		{Title: "ID", Width: 4},
+		{Title: "ID", Width: 6},
 		{Title: "Time", Width: 16},
 		{Title: "App", Width: 20},
 		{Title: "Title", Width: 40},
@@ -134,18 +193,159 @@ func (m *model) initTable() {
 
 func (m *model) updateRows() {
 	rows := make([]table.Row, 0, len(m.entries))
-	for _, e := range m.entries {
+	selected := m.selectedSet()
+	for i, e := range m.entries {
 		ts := e.Capture.Ts.Local().Format("2006-01-02 15:04")
+		id := fmt.Sprintf("%d", e.ID)
+		if selected[i] {
+			id = "● " + id
+		}
+		title := e.ActiveWindow.Title
+		if e.IsVideo() {
+			title = fmt.Sprintf("▶ %s %s", formatDuration(e.Duration), title)
+		}
 		rows = append(rows, table.Row{
-			fmt.Sprintf("%d", e.ID),
+			id,
 			ts,
 			e.ActiveWindow.Class,
-			e.ActiveWindow.Title,
+			title,
 		})
 	}
 	m.table.SetRows(rows)
 }
 
+// formatDuration renders a recording length as m:ss.
+func formatDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// selectedSet returns the entry indices covered by marks and the visual range.
+func (m *model) selectedSet() map[int]bool {
+	set := make(map[int]bool)
+	for i, e := range m.entries {
+		if m.marked[e.ID] {
+			set[i] = true
+		}
+	}
+	if m.visual {
+		lo, hi := m.visualFrom, m.table.Cursor()
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := lo; i <= hi && i < len(m.entries); i++ {
+			if i >= 0 {
+				set[i] = true
+			}
+		}
+	}
+	return set
+}
+
+// selection returns the entries an action applies to: the multi-selection
+// if there is one, otherwise the entry under the cursor.
+func (m *model) selection() []models.Screenshot {
+	set := m.selectedSet()
+	if len(set) == 0 {
+		idx := m.table.Cursor()
+		if idx >= 0 && idx < len(m.entries) {
+			return []models.Screenshot{m.entries[idx]}
+		}
+		return nil
+	}
+	sel := make([]models.Screenshot, 0, len(set))
+	for i, e := range m.entries {
+		if set[i] {
+			sel = append(sel, e)
+		}
+	}
+	return sel
+}
+
+// reload fetches the entries again, honouring the album filter and the
+// search query.
+func (m *model) reload() error {
+	entries, err := m.store.FindScreenshots(db.ListOptions{Album: m.album, WithClients: true})
+	if err != nil {
+		return err
+	}
+	m.all = entries
+	m.filter()
+	if m.table.Cursor() >= len(m.entries) {
+		m.table.SetCursor(max(len(m.entries)-1, 0))
+	}
+	return nil
+}
+
+// filter ranks all against the search query into entries.
+func (m *model) filter() {
+	if strings.TrimSpace(m.query) == "" {
+		m.entries = m.all
+	} else {
+		m.entries = search.Screenshots(m.all, m.query, time.Now())
+	}
+	m.updateRows()
+}
+
+// updateSearch handles a key while the query is being typed.
+func (m *model) updateSearch(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		return
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.searching = false
+		m.query = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.query); len(r) > 0 {
+			m.query = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.query += " "
+	case tea.KeyRunes:
+		m.query += string(msg.Runes)
+	default:
+		return
+	}
+	m.marked = make(map[int64]bool)
+	m.visual = false
+	m.filter()
+	m.table.SetCursor(0)
+}
+
+// nextAlbum returns the album after the current filter, cycling through
+// all albums and back to no filter.
+func (m *model) nextAlbum() (string, error) {
+	albums, err := m.store.ListAlbums()
+	if err != nil {
+		return "", err
+	}
+	if m.album == "" {
+		if len(albums) == 0 {
+			return "", nil
+		}
+		return albums[0].Name, nil
+	}
+	for i, a := range albums {
+		if a.Name == m.album && i+1 < len(albums) {
+			return albums[i+1].Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *model) clearSelection() {
+	m.marked = make(map[int64]bool)
+	m.visual = false
+	m.updateRows()
+}
+
+// editFinishedMsg is sent when `orego edit` returns control to the TUI.
+type editFinishedMsg struct {
+	id  int64
+	err error
+}
+
 func (m model) Init() tea.Cmd { return nil }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -156,39 +356,119 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.applyLayout()
 		return m, nil
 
+	case editFinishedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Edit failed: %v", msg.err)
+			return m, nil
+		}
+		m.reload()
+		m.status = fmt.Sprintf("Edited ID %d", msg.id)
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.searching {
+			m.updateSearch(msg)
+			return m, nil
+		}
+		if m.showStats && !key.Matches(msg, m.keys.Stats, m.keys.Help, m.keys.Quit) {
+			return m, nil
+		}
 		switch {
+		case key.Matches(msg, m.keys.Stats):
+			m.showStats = !m.showStats
+			m.status = ""
+			return m, nil
+		case msg.String() == "esc" && (m.visual || len(m.marked) > 0):
+			m.clearSelection()
+			m.status = "Selection cleared"
+			return m, nil
+		case msg.String() == "esc" && m.query != "":
+			m.query = ""
+			m.filter()
+			m.status = "Search cleared"
+			return m, nil
+		case key.Matches(msg, m.keys.Search):
+			m.searching = true
+			m.status = ""
+			return m, nil
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
+		case key.Matches(msg, m.keys.Mark):
+			idx := m.table.Cursor()
+			if idx >= 0 && idx < len(m.entries) {
+				id := m.entries[idx].ID
+				if m.marked[id] {
+					delete(m.marked, id)
+				} else {
+					m.marked[id] = true
+				}
+				m.table.MoveDown(1)
+				m.updateRows()
+				m.status = fmt.Sprintf("%d selected", len(m.selectedSet()))
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Visual):
+			if m.visual {
+				// Commit the range into the marks.
+				for i := range m.selectedSet() {
+					m.marked[m.entries[i].ID] = true
+				}
+				m.visual = false
+			} else {
+				m.visual = true
+				m.visualFrom = m.table.Cursor()
+			}
+			m.updateRows()
+			m.status = fmt.Sprintf("%d selected", len(m.selectedSet()))
+			return m, nil
+		case key.Matches(msg, m.keys.Album):
+			album, err := m.nextAlbum()
+			if err != nil {
+				m.status = fmt.Sprintf("Listing albums failed: %v", err)
+				return m, nil
+			}
+			m.album = album
+			m.marked = make(map[int64]bool)
+			m.visual = false
+			if err := m.reload(); err != nil {
+				m.status = fmt.Sprintf("Filter failed: %v", err)
+				return m, nil
+			}
+			if album == "" {
+				m.status = "Showing all screenshots"
+			} else {
+				m.status = fmt.Sprintf("Album: %s", album)
+			}
+			return m, nil
 		case key.Matches(msg, m.keys.Help):
 			m.showHelp = !m.showHelp
 			return m, nil
 		case key.Matches(msg, m.keys.Open):
-			idx := m.table.Cursor()
-			if idx >= 0 && idx < len(m.entries) {
-				sel := m.entries[idx]
-				_ = exec.Command("xdg-open", sel.FilePath).Start()
-				m.status = fmt.Sprintf("Opened %s", sel.FilePath)
+			sel := m.selection()
+			for _, e := range sel {
+				if err := actions.OpenFile(m.cfg, e.FilePath); err != nil {
+					m.status = fmt.Sprintf("Open failed: %v", err)
+					return m, nil
+				}
+			}
+			switch len(sel) {
+			case 0:
+			case 1:
+				m.status = fmt.Sprintf("Opened %s", sel[0].FilePath)
+			default:
+				m.status = fmt.Sprintf("Opened %d screenshots", len(sel))
+				m.clearSelection()
 			}
 			return m, nil
 		case key.Matches(msg, m.keys.CopyImage):
+			if len(m.selection()) > 1 {
+				m.status = "Only one image fits on the clipboard; use C/Y to copy paths"
+				return m, nil
+			}
 			idx := m.table.Cursor()
 			if idx >= 0 && idx < len(m.entries) {
 				sel := m.entries[idx]
-				file, err := os.Open(sel.FilePath)
-				if err != nil {
-					if os.IsNotExist(err) {
-						m.status = fmt.Sprintf("Missing file: %s", sel.FilePath)
-					} else {
-						m.status = fmt.Sprintf("Open failed: %v", err)
-					}
-					return m, nil
-				}
-				defer file.Close()
-
-				copyCmd := exec.Command("wl-copy", "--type", "image/png")
-				copyCmd.Stdin = file
-				if err := copyCmd.Run(); err != nil {
+				if err := actions.CopyImage(m.cfg, sel.FilePath, actions.MimeType(&sel)); err != nil {
 					m.status = fmt.Sprintf("Copy failed: %v", err)
 					return m, nil
 				}
@@ -199,70 +479,104 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			idx := m.table.Cursor()
 			if idx >= 0 && idx < len(m.entries) {
 				sel := m.entries[idx]
-				if _, err := os.Stat(sel.FilePath); err != nil {
-					if os.IsNotExist(err) {
-						m.status = fmt.Sprintf("Missing file: %s", sel.FilePath)
-					} else {
-						m.status = fmt.Sprintf("Stat failed: %v", err)
-					}
-					return m, nil
-				}
-				folder := filepath.Dir(sel.FilePath)
-				if err := exec.Command("xdg-open", folder).Start(); err != nil {
+				if err := actions.OpenFolderOf(m.cfg, sel.FilePath); err != nil {
 					m.status = fmt.Sprintf("Open folder failed: %v", err)
 					return m, nil
 				}
-				m.status = fmt.Sprintf("Opened %s", folder)
+				m.status = fmt.Sprintf("Opened %s", filepath.Dir(sel.FilePath))
 			}
 			return m, nil
 		case key.Matches(msg, m.keys.CopyFolder):
-			idx := m.table.Cursor()
-			if idx >= 0 && idx < len(m.entries) {
-				sel := m.entries[idx]
-				if _, err := os.Stat(sel.FilePath); err != nil {
+			sel := m.selection()
+			if len(sel) == 0 {
+				return m, nil
+			}
+			paths := make([]string, 0, len(sel))
+			for _, e := range sel {
+				if _, err := os.Stat(e.FilePath); err != nil {
 					if os.IsNotExist(err) {
-						m.status = fmt.Sprintf("Missing file: %s", sel.FilePath)
+						m.status = fmt.Sprintf("Missing file: %s", e.FilePath)
 					} else {
 						m.status = fmt.Sprintf("Stat failed: %v", err)
 					}
 					return m, nil
 				}
-				copyCmd := exec.Command("wl-copy")
-				copyCmd.Stdin = strings.NewReader(sel.FilePath)
-				if err := copyCmd.Run(); err != nil {
-					m.status = fmt.Sprintf("Copy path failed: %v", err)
-					return m, nil
-				}
+				paths = append(paths, e.FilePath)
+			}
+			if err := actions.CopyText(m.cfg, strings.Join(paths, "\n")); err != nil {
+				m.status = fmt.Sprintf("Copy path failed: %v", err)
+				return m, nil
+			}
+			if len(paths) == 1 {
 				m.status = "Copied path to clipboard"
+			} else {
+				m.status = fmt.Sprintf("Copied %d paths to clipboard", len(paths))
+				m.clearSelection()
 			}
 			return m, nil
-		case key.Matches(msg, m.keys.Delete):
+		case key.Matches(msg, m.keys.Edit):
 			idx := m.table.Cursor()
-			if idx >= 0 && idx < len(m.entries) {
-				sel := m.entries[idx]
-				if err := m.store.DeleteScreenshot(sel.ID); err == nil {
-					// Remove from slice
-					m.entries = append(m.entries[:idx], m.entries[idx+1:]...)
-					m.updateRows()
-					// Adjust cursor
-					if idx >= len(m.entries) {
-						m.table.SetCursor(len(m.entries) - 1)
-					}
-					m.status = fmt.Sprintf("Deleted ID %d", sel.ID)
-				} else {
-					m.status = fmt.Sprintf("Error deleting: %v", err)
-				}
+			if idx < 0 || idx >= len(m.entries) {
+				return m, nil
+			}
+			id := m.entries[idx].ID
+			exe, err := os.Executable()
+			if err != nil {
+				m.status = fmt.Sprintf("Edit failed: %v", err)
+				return m, nil
+			}
+			// Run the edit command in the foreground so the editor and its
+			// revision bookkeeping behave exactly like `orego edit`.
+			editCmd := exec.Command(exe, "edit", fmt.Sprintf("%d", id))
+			return m, tea.ExecProcess(editCmd, func(err error) tea.Msg {
+				return editFinishedMsg{id: id, err: err}
+			})
+		case key.Matches(msg, m.keys.Delete):
+			sel := m.selection()
+			if len(sel) == 0 {
+				return m, nil
+			}
+			ids := make([]int64, 0, len(sel))
+			deleted := make(map[int64]bool, len(sel))
+			for _, e := range sel {
+				ids = append(ids, e.ID)
+				deleted[e.ID] = true
+			}
+			if err := m.store.DeleteScreenshots(ids); err != nil {
+				m.status = fmt.Sprintf("Error deleting: %v", err)
+				return m, nil
+			}
+			// Remove from slice
+			m.all = slices.DeleteFunc(m.all, func(e models.Screenshot) bool { return deleted[e.ID] })
+			m.marked = make(map[int64]bool)
+			m.visual = false
+			m.filter()
+			// Adjust cursor
+			if m.table.Cursor() >= len(m.entries) {
+				m.table.SetCursor(len(m.entries) - 1)
+			}
+			if len(ids) == 1 {
+				m.status = fmt.Sprintf("Deleted ID %d", ids[0])
+			} else {
+				m.status = fmt.Sprintf("Deleted %d screenshots", len(ids))
 			}
 			return m, nil
 		}
 	}
 	var cmd tea.Cmd
 	m.table, cmd = m.table.Update(msg)
+	if m.visual {
+		m.updateRows()
+	}
 	return m, cmd
 }
 
 func (m model) View() string {
-	base := m.table.View() + "\n" + m.renderFooter()
+	body := m.table.View()
+	if m.showStats {
+		body = lipgloss.NewStyle().Height(max(0, m.height-1)).Render(m.statsView())
+	}
+	base := body + "\n" + m.renderFooter()
 	if m.showHelp {
 		helpView, w, h := m.helpModalView()
 		return m.renderOverlay(base, helpView, w, h)
@@ -272,7 +586,19 @@ func (m model) View() string {
 
 func (m model) renderFooter() string {
 	left := "? for help"
+	if m.searching {
+		left = "/" + m.query + "▏"
+	}
 	right := fmt.Sprintf("%d items", len(m.entries))
+	if m.query != "" && !m.searching {
+		right = fmt.Sprintf("search %q • %s", m.query, right)
+	}
+	if m.album != "" {
+		right = fmt.Sprintf("album %s • %s", m.album, right)
+	}
+	if n := len(m.selectedSet()); n > 0 {
+		right = fmt.Sprintf("%d selected • %s", n, right)
+	}
 	if m.status != "" {
 		right = m.status + " • " + right
 	}
@@ -341,7 +667,7 @@ func (m *model) applyLayout() {
 	m.table.SetWidth(m.width)
 
 	// Dynamic column width
-	avail := m.width - 4 - 24 // approximate fixed widths for ID and Time
+	avail := m.width - 6 - 24 // approximate fixed widths for ID and Time
 	if avail > 20 {
 		appW := avail / 3
 		titleW := avail - appW
@@ -0,0 +1,127 @@
+package vault
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+)
+
+// The agent is a short-lived background process that keeps the decrypted
+// identity in memory and hands it to other orego processes over a unix
+// socket only the current user can reach. It exits when locked or when
+// its TTL expires, wiping the decrypted image cache on the way out.
+
+func SocketPath() (string, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vault.sock"), nil
+}
+
+// Serve runs the agent until it is locked or ttl elapses.
+func Serve(identity *age.X25519Identity, ttl time.Duration) error {
+	sock, err := SocketPath()
+	if err != nil {
+		return err
+	}
+	os.Remove(sock)
+
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", sock, err)
+	}
+	if err := os.Chmod(sock, 0600); err != nil {
+		ln.Close()
+		return err
+	}
+	defer func() {
+		ln.Close()
+		os.Remove(sock)
+		WipeCache()
+	}()
+
+	expires := time.Now().Add(ttl)
+	timer := time.AfterFunc(ttl, func() { ln.Close() })
+	defer timer.Stop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			// The listener is closed when the TTL expires.
+			return nil
+		}
+
+		if err := checkPeer(conn); err != nil {
+			fmt.Fprintf(os.Stderr, "orego vault agent: rejected connection: %v\n", err)
+			conn.Close()
+			continue
+		}
+
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		switch strings.TrimSpace(line) {
+		case "identity":
+			fmt.Fprintln(conn, identity.String())
+		case "status":
+			fmt.Fprintln(conn, expires.Format(time.RFC3339))
+		case "lock":
+			fmt.Fprintln(conn, "ok")
+			conn.Close()
+			return nil
+		default:
+			fmt.Fprintln(conn, "error: unknown request")
+		}
+		conn.Close()
+	}
+}
+
+func agentRequest(request string) (string, error) {
+	sock, err := SocketPath()
+	if err != nil {
+		return "", err
+	}
+	conn, err := net.DialTimeout("unix", sock, time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if _, err := fmt.Fprintln(conn, request); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	reply = strings.TrimSpace(reply)
+	if msg, ok := strings.CutPrefix(reply, "error: "); ok {
+		return "", fmt.Errorf("agent: %s", msg)
+	}
+	return reply, nil
+}
+
+// AgentExpiry returns when the running agent will lock the vault.
+func AgentExpiry() (time.Time, error) {
+	reply, err := agentRequest("status")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, reply)
+}
+
+// Lock stops the running agent, if any, and wipes decrypted copies.
+func Lock() error {
+	_, err := agentRequest("lock")
+	if werr := WipeCache(); err == nil {
+		err = werr
+	}
+	return err
+}
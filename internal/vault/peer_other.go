@@ -0,0 +1,14 @@
+//go:build !linux
+
+package vault
+
+import (
+	"fmt"
+	"net"
+)
+
+// checkPeer cannot read peer credentials here, so the agent refuses to
+// hand out the identity rather than trust the socket permissions alone.
+func checkPeer(conn net.Conn) error {
+	return fmt.Errorf("peer credentials are not supported on this platform")
+}
@@ -0,0 +1,416 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"filippo.io/age"
+)
+
+const (
+	// EncryptedExt is appended to image files stored in the vault.
+	EncryptedExt = ".age"
+	// fieldPrefix marks DB values sealed with the vault recipient.
+	fieldPrefix = "age:"
+	// LockedPlaceholder replaces sealed values while the vault is locked.
+	LockedPlaceholder = "[locked]"
+)
+
+// ErrLocked is returned when decryption is needed but no agent is running.
+var ErrLocked = errors.New("vault is locked; run 'orego vault unlock'")
+
+// Vault holds the public recipient used to encrypt new data and, while
+// unlocked, the identity used to decrypt it.
+type Vault struct {
+	recipient *age.X25519Recipient
+	identity  *age.X25519Identity
+}
+
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local/share/orego/vault"), nil
+}
+
+func recipientPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recipient.txt"), nil
+}
+
+func identityPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "identity.age"), nil
+}
+
+// Initialized reports whether `orego vault init` has been run.
+func Initialized() bool {
+	path, err := recipientPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Init generates a new X25519 identity, stores it encrypted with the
+// passphrase and returns the resulting vault in unlocked state.
+func Init(passphrase string) (*Vault, error) {
+	if Initialized() {
+		return nil, fmt.Errorf("vault already initialized")
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity: %w", err)
+	}
+
+	scrypt, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var sealed bytes.Buffer
+	w, err := age.Encrypt(&sealed, scrypt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, identity.String()); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create vault dir: %w", err)
+	}
+
+	idPath, _ := identityPath()
+	if err := os.WriteFile(idPath, sealed.Bytes(), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write identity: %w", err)
+	}
+	recPath, _ := recipientPath()
+	if err := os.WriteFile(recPath, []byte(identity.Recipient().String()+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write recipient: %w", err)
+	}
+
+	return &Vault{recipient: identity.Recipient(), identity: identity}, nil
+}
+
+// DecryptIdentity unwraps the stored identity with the passphrase.
+func DecryptIdentity(passphrase string) (*age.X25519Identity, error) {
+	idPath, err := identityPath()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := os.ReadFile(idPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity: %w", err)
+	}
+
+	scrypt, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(sealed), scrypt)
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase")
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return age.ParseX25519Identity(strings.TrimSpace(string(raw)))
+}
+
+// Load returns the vault, or nil if it was never initialized. The identity
+// is fetched from the agent when one is running.
+func Load() (*Vault, error) {
+	if !Initialized() {
+		return nil, nil
+	}
+
+	recPath, err := recipientPath()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(recPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipient: %w", err)
+	}
+	recipient, err := age.ParseX25519Recipient(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	v := &Vault{recipient: recipient}
+	if reply, err := agentRequest("identity"); err == nil {
+		if identity, err := age.ParseX25519Identity(reply); err == nil {
+			v.identity = identity
+		}
+	}
+	return v, nil
+}
+
+var current struct {
+	once  sync.Once
+	vault *Vault
+	err   error
+}
+
+// Current returns the process-wide vault, loading it on first use.
+func Current() (*Vault, error) {
+	current.once.Do(func() {
+		current.vault, current.err = Load()
+	})
+	return current.vault, current.err
+}
+
+func (v *Vault) Unlocked() bool {
+	return v != nil && v.identity != nil
+}
+
+// Seal encrypts a DB value. Empty strings are stored as is.
+func (v *Vault) Seal(s string) (string, error) {
+	if s == "" || strings.HasPrefix(s, fieldPrefix) {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, v.recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return fieldPrefix + base64.RawStdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Open decrypts a value produced by Seal. Plain values are returned
+// unchanged; sealed values read while locked become LockedPlaceholder.
+func (v *Vault) Open(s string) (string, error) {
+	encoded, ok := strings.CutPrefix(s, fieldPrefix)
+	if !ok {
+		return s, nil
+	}
+	if !v.Unlocked() {
+		return LockedPlaceholder, nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed value: %w", err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(raw), v.identity)
+	if err != nil {
+		return "", err
+	}
+	plain, err := io.ReadAll(r)
+	return string(plain), err
+}
+
+// EncryptFile encrypts path to path+EncryptedExt, removes the plaintext
+// and returns the new path.
+func (v *Vault) EncryptFile(path string) (string, error) {
+	if IsEncrypted(path) {
+		return path, nil
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	encPath := path + EncryptedExt
+	out, err := os.OpenFile(encPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	w, err := age.Encrypt(out, v.recipient)
+	if err == nil {
+		_, err = io.Copy(w, in)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(encPath)
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+
+	in.Close()
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return encPath, nil
+}
+
+func IsEncrypted(path string) bool {
+	return strings.HasSuffix(path, EncryptedExt)
+}
+
+// PlainName strips the vault extension from an image path.
+func PlainName(path string) string {
+	return strings.TrimSuffix(path, EncryptedExt)
+}
+
+// OpenImage opens an image for reading, decrypting it if it is stored in
+// the vault.
+func OpenImage(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil || !IsEncrypted(path) {
+		return f, err
+	}
+
+	v, err := Current()
+	if err == nil && !v.Unlocked() {
+		err = ErrLocked
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r, err := age.Decrypt(f, v.identity)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// PlainPath returns a path external programs can read. Encrypted images
+// are decrypted into a private cache under the runtime dir, which is
+// wiped when the vault is locked.
+func PlainPath(path string) (string, error) {
+	if !IsEncrypted(path) {
+		return path, nil
+	}
+
+	cacheDir, err := plainCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	cached := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+"_"+filepath.Base(PlainName(path)))
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	r, err := OpenImage(path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(cacheDir, ".plain-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return cached, os.Rename(tmp.Name(), cached)
+}
+
+// runtimeDir is a per-user directory for the agent socket and decrypted
+// cache, preferably on tmpfs. Without XDG_RUNTIME_DIR it falls back to a
+// predictable path in the shared temp dir, so both levels are checked to
+// be private before anything is put there.
+func runtimeDir() (string, error) {
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		base = filepath.Join(os.TempDir(), fmt.Sprintf("orego-%d", os.Getuid()))
+		if err := os.Mkdir(base, 0700); err != nil && !os.IsExist(err) {
+			return "", err
+		}
+		if err := checkPrivateDir(base); err != nil {
+			return "", err
+		}
+	}
+	dir := filepath.Join(base, "orego")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// checkPrivateDir refuses dir unless it is a real directory owned by the
+// current user that nobody else can access.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by uid %d, not %d", dir, st.Uid, os.Getuid())
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("%s has mode %04o, want 0700", dir, perm)
+	}
+	return nil
+}
+
+func plainCacheDir() (string, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plain"), nil
+}
+
+// WipeCache removes all decrypted copies.
+func WipeCache() error {
+	dir, err := plainCacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
@@ -13,9 +13,15 @@ import (
 
 // HyprMonitor represents the JSON output from 'hyprctl monitors -j'
 type HyprMonitor struct {
-	ID              int    `json:"id"`
-	Name            string `json:"name"`
-	Focused         bool   `json:"focused"`
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Focused         bool    `json:"focused"`
+	X               int     `json:"x"`
+	Y               int     `json:"y"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	Scale           float64 `json:"scale"`
+	Transform       int     `json:"transform"`
 	ActiveWorkspace struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
@@ -32,27 +38,59 @@ type HyprWindow struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"workspace"`
-	Floating   bool `json:"floating"`
-	Fullscreen int  `json:"fullscreen"` // 0: no, 1: maximize, 2: fullscreen
-	Xwayland   bool `json:"xwayland"`
-	Pinned     bool `json:"pinned"`
+	Floating   bool   `json:"floating"`
+	Fullscreen int    `json:"fullscreen"` // 0: no, 1: maximize, 2: fullscreen
+	Xwayland   bool   `json:"xwayland"`
+	Pinned     bool   `json:"pinned"`
+	At         [2]int `json:"at"`
+	Size       [2]int `json:"size"`
 }
 
-func runHyprctl(args ...string) ([]byte, error) {
+// runHyprctl executes hyprctl. It is a variable so tests can substitute
+// canned JSON for a running compositor.
+var runHyprctl = func(args ...string) ([]byte, error) {
 	cmd := exec.Command("hyprctl", args...)
 	return cmd.Output()
 }
 
-func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
+// logicalGeometry returns the monitor rectangle in layout coordinates.
+func (m HyprMonitor) logicalGeometry() models.Geometry {
+	scale := m.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	w, h := m.Width, m.Height
+	if m.Transform%2 == 1 {
+		w, h = h, w
+	}
+	return models.Geometry{
+		X:      m.X,
+		Y:      m.Y,
+		Width:  int(float64(w) / scale),
+		Height: int(float64(h) / scale),
+	}
+}
+
+// ActiveWindow returns the focused window. The result is empty when no
+// window has focus (e.g. the desktop is focused).
+func ActiveWindow() (HyprWindow, error) {
 	rawActive, err := runHyprctl("activewindow", "-j")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active window: %w", err)
+		return HyprWindow{}, fmt.Errorf("failed to get active window: %w", err)
 	}
 	var activeWin HyprWindow
 	if len(rawActive) > 0 && string(rawActive) != "{}" {
 		// Ignore error here as empty active window is possible (e.g. desktop focused)
 		_ = json.Unmarshal(rawActive, &activeWin)
 	}
+	return activeWin, nil
+}
+
+func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
+	activeWin, err := ActiveWindow()
+	if err != nil {
+		return nil, err
+	}
 
 	rawMonitors, err := runHyprctl("monitors", "-j")
 	if err != nil {
@@ -66,7 +104,7 @@ func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
 	var activeMon HyprMonitor
 	visibleWorkspaceIDs := make(map[int]bool)
 	foundMon := false
-	
+
 	for _, m := range monitors {
 		visibleWorkspaceIDs[m.ActiveWorkspace.ID] = true
 		if m.Focused {
@@ -115,6 +153,12 @@ func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
 				Title:       c.Title,
 				Pid:         c.Pid,
 				WorkspaceID: c.Workspace.ID,
+				Geometry: &models.Geometry{
+					X:      c.At[0],
+					Y:      c.At[1],
+					Width:  c.Size[0],
+					Height: c.Size[1],
+				},
 			})
 		}
 	}
@@ -125,10 +169,25 @@ func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
 	if currentUser != nil {
 		username = currentUser.Username
 	}
-	
+
 	monitorName := activeMon.Name
+	region := activeMon.logicalGeometry()
+	scale := activeMon.Scale
 	if captureAll {
 		monitorName = "all-visible"
+		// grim renders the whole layout at the highest output scale.
+		for i, m := range monitors {
+			g := m.logicalGeometry()
+			if i == 0 {
+				region = g
+			} else {
+				region = region.Union(g)
+			}
+			scale = max(scale, m.Scale)
+		}
+	}
+	if scale <= 0 {
+		scale = 1
 	}
 
 	data := &models.Screenshot{
@@ -140,6 +199,7 @@ func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
 			Command:  "orego capture",
 			Version:  "0.1.0",
 		},
+		Origin: models.OriginCapture,
 		ActiveWindow: models.ActiveWindow{
 			Address: activeWin.Address,
 			Class:   activeWin.Class,
@@ -161,6 +221,8 @@ func GetScreenshotData(captureAll bool) (*models.Screenshot, error) {
 			LastWindowTitle: lastWindowTitle,
 		},
 		Clients: workspaceClients,
+		Region:  &region,
+		Scale:   scale,
 	}
 
 	tzName, _ := time.Now().Zone()
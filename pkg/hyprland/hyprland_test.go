@@ -0,0 +1,194 @@
+package hyprland
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"orego/internal/config"
+	"orego/internal/privacy"
+	"orego/pkg/models"
+)
+
+// A laptop panel with workspace 2 in focus and a HiDPI screen beside it
+// showing workspace 5. Workspace 9 is not visible.
+const (
+	activeJSON = `{"address":"0x1","class":"firefox","title":"Online Banking - Mozilla Firefox","pid":100,
+		"workspace":{"id":2,"name":"2"},"fullscreen":0,"at":[0,0],"size":[1280,1440]}`
+	monitorsJSON = `[
+		{"id":0,"name":"eDP-1","focused":true,"x":0,"y":0,"width":2560,"height":1440,"scale":1,
+			"activeWorkspace":{"id":2,"name":"2"}},
+		{"id":1,"name":"DP-2","focused":false,"x":2560,"y":0,"width":3840,"height":2160,"scale":2,
+			"activeWorkspace":{"id":5,"name":"5"}}
+	]`
+	clientsJSON = `[
+		{"address":"0x1","class":"firefox","title":"Online Banking - Mozilla Firefox","pid":100,
+			"workspace":{"id":2,"name":"2"},"at":[0,0],"size":[1280,1440]},
+		{"address":"0x2","class":"org.keepassxc.KeePassXC","title":"Passwords.kdbx - KeePassXC","pid":101,
+			"workspace":{"id":2,"name":"2"},"at":[1280,0],"size":[1280,720]},
+		{"address":"0x3","class":"signal","title":"Signal","pid":102,
+			"workspace":{"id":5,"name":"5"},"at":[2560,0],"size":[1920,1080]},
+		{"address":"0x4","class":"slack","title":"Slack","pid":103,
+			"workspace":{"id":9,"name":"9"},"at":[0,0],"size":[2560,1440]}
+	]`
+)
+
+// fakeHyprctl answers hyprctl queries from replies, keyed by the first
+// argument, for the rest of the test.
+func fakeHyprctl(t *testing.T, replies map[string]string) {
+	t.Helper()
+	orig := runHyprctl
+	t.Cleanup(func() { runHyprctl = orig })
+	runHyprctl = func(args ...string) ([]byte, error) {
+		reply, ok := replies[args[0]]
+		if !ok {
+			return nil, errors.New("exit status 1")
+		}
+		return []byte(reply), nil
+	}
+}
+
+func desktop(t *testing.T) {
+	fakeHyprctl(t, map[string]string{
+		"activewindow": activeJSON,
+		"monitors":     monitorsJSON,
+		"clients":      clientsJSON,
+	})
+}
+
+func classes(clients []models.Client) []string {
+	var names []string
+	for _, c := range clients {
+		names = append(names, c.Class)
+	}
+	return names
+}
+
+func TestGetScreenshotDataFocusedMonitor(t *testing.T) {
+	desktop(t)
+
+	sc, err := GetScreenshotData(false)
+	if err != nil {
+		t.Fatalf("GetScreenshotData: %v", err)
+	}
+
+	if sc.ActiveWindow.Class != "firefox" || sc.ActiveWindow.Pid != 100 {
+		t.Errorf("ActiveWindow = %+v, want firefox with pid 100", sc.ActiveWindow)
+	}
+	if sc.Workspace.ID != 2 || sc.Workspace.Monitor != "eDP-1" || sc.Workspace.Windows != 2 {
+		t.Errorf("Workspace = %+v, want workspace 2 on eDP-1 with 2 windows", sc.Workspace)
+	}
+	if want := []string{"firefox", "org.keepassxc.KeePassXC"}; !slices.Equal(classes(sc.Clients), want) {
+		t.Errorf("Clients = %v, want %v", classes(sc.Clients), want)
+	}
+	if want := (models.Geometry{Width: 2560, Height: 1440}); sc.Region == nil || *sc.Region != want {
+		t.Errorf("Region = %v, want %v", sc.Region, want)
+	}
+	if sc.Scale != 1 {
+		t.Errorf("Scale = %v, want 1", sc.Scale)
+	}
+}
+
+func TestGetScreenshotDataAllMonitors(t *testing.T) {
+	desktop(t)
+
+	sc, err := GetScreenshotData(true)
+	if err != nil {
+		t.Fatalf("GetScreenshotData: %v", err)
+	}
+
+	if sc.Workspace.Monitor != "all-visible" {
+		t.Errorf("Monitor = %q, want all-visible", sc.Workspace.Monitor)
+	}
+	// Clients of both visible workspaces, not of hidden ones.
+	if want := []string{"firefox", "org.keepassxc.KeePassXC", "signal"}; !slices.Equal(classes(sc.Clients), want) {
+		t.Errorf("Clients = %v, want %v", classes(sc.Clients), want)
+	}
+	// The HiDPI screen is 1920x1080 in layout coordinates; grim renders
+	// the whole layout at its scale.
+	if want := (models.Geometry{Width: 4480, Height: 1440}); sc.Region == nil || *sc.Region != want {
+		t.Errorf("Region = %v, want %v", sc.Region, want)
+	}
+	if sc.Scale != 2 {
+		t.Errorf("Scale = %v, want 2", sc.Scale)
+	}
+}
+
+func TestGetScreenshotDataPrivacy(t *testing.T) {
+	rules, err := privacy.CompileRules([]config.PrivacyRule{
+		{Name: "password-manager", Class: "KeePassXC", Action: "blur-window"},
+		{Name: "banking", Title: "(?i)bank", Action: "no-metadata"},
+		{Name: "chat", Class: "^signal$", Action: "block"},
+	})
+	if err != nil {
+		t.Fatalf("CompileRules: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		all        bool
+		blockedBy  string
+		noMetadata bool
+		blur       []models.Geometry
+	}{
+		{
+			name:       "focused monitor",
+			noMetadata: true,
+			blur:       []models.Geometry{{X: 1280, Width: 1280, Height: 720}},
+		},
+		{
+			name:       "all monitors",
+			all:        true,
+			blockedBy:  "chat",
+			noMetadata: true,
+			blur:       []models.Geometry{{X: 1280, Width: 1280, Height: 720}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desktop(t)
+			sc, err := GetScreenshotData(tt.all)
+			if err != nil {
+				t.Fatalf("GetScreenshotData: %v", err)
+			}
+
+			d := privacy.Evaluate(rules, sc)
+			if d.BlockedBy != tt.blockedBy {
+				t.Errorf("BlockedBy = %q, want %q", d.BlockedBy, tt.blockedBy)
+			}
+			if d.NoMetadata != tt.noMetadata {
+				t.Errorf("NoMetadata = %v, want %v", d.NoMetadata, tt.noMetadata)
+			}
+			if !slices.Equal(d.Blur, tt.blur) {
+				t.Errorf("Blur = %v, want %v", d.Blur, tt.blur)
+			}
+		})
+	}
+}
+
+func TestGetScreenshotDataNoFocus(t *testing.T) {
+	fakeHyprctl(t, map[string]string{
+		"activewindow": "{}",
+		"monitors":     monitorsJSON,
+		"clients":      "[]",
+	})
+
+	sc, err := GetScreenshotData(false)
+	if err != nil {
+		t.Fatalf("GetScreenshotData: %v", err)
+	}
+	if sc.ActiveWindow != (models.ActiveWindow{}) {
+		t.Errorf("ActiveWindow = %+v, want empty", sc.ActiveWindow)
+	}
+	if sc.Workspace.ID != 2 || len(sc.Clients) != 0 {
+		t.Errorf("Workspace, Clients = %+v, %v, want workspace 2 without clients", sc.Workspace, sc.Clients)
+	}
+}
+
+func TestGetScreenshotDataHyprctlFails(t *testing.T) {
+	fakeHyprctl(t, map[string]string{"activewindow": activeJSON})
+
+	if _, err := GetScreenshotData(false); err == nil {
+		t.Error("GetScreenshotData succeeded without monitors")
+	}
+}
@@ -35,12 +35,92 @@ type Workspace struct {
 	LastWindowTitle string `json:"last_window_title"`
 }
 
+// Geometry is a rectangle in Hyprland's logical layout coordinates.
+type Geometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Union returns the smallest geometry containing both g and o.
+func (g Geometry) Union(o Geometry) Geometry {
+	x0, y0 := min(g.X, o.X), min(g.Y, o.Y)
+	x1, y1 := max(g.X+g.Width, o.X+o.Width), max(g.Y+g.Height, o.Y+o.Height)
+	return Geometry{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
 type Client struct {
-	Address     string `json:"address"`
-	Class       string `json:"class"`
-	Title       string `json:"title"`
-	Pid         int    `json:"pid"`
-	WorkspaceID int    `json:"workspace"`
+	Address     string    `json:"address"`
+	Class       string    `json:"class"`
+	Title       string    `json:"title"`
+	Pid         int       `json:"pid"`
+	WorkspaceID int       `json:"workspace"`
+	Geometry    *Geometry `json:"geometry,omitempty"` // Only known at capture time
+}
+
+// Redaction records how many regions a redaction rule blurred out.
+type Redaction struct {
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// Revision is one saved version of a screenshot. Revision 0 is the
+// original capture. The image details are unknown (zero) for revisions
+// recorded by older versions.
+type Revision struct {
+	Number    int       `json:"number"`
+	FilePath  string    `json:"file_path"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	Width     int       `json:"width,omitempty"`
+	Height    int       `json:"height,omitempty"`
+	Size      int64     `json:"size,omitempty"` // Bytes, before vault encryption
+}
+
+// Share records an upload of a screenshot and the link it produced.
+type Share struct {
+	ID        int64     `json:"id"`
+	Target    string    `json:"target"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session groups captures taken together, e.g. a time-lapse sequence.
+type Session struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Count     int       `json:"count"`
+	First     time.Time `json:"first,omitempty"`
+	Last      time.Time `json:"last,omitempty"`
+}
+
+// Album is a user-curated group of screenshots.
+type Album struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Count       int       `json:"count"`
+}
+
+// Media types stored in Screenshot.MediaType.
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// Where an entry came from, stored in Screenshot.Origin.
+const (
+	OriginCapture   = "capture"
+	OriginClipboard = "clipboard"
+)
+
+// IsVideo reports whether the entry is a screen recording.
+func (sc Screenshot) IsVideo() bool {
+	return sc.MediaType == MediaVideo
 }
 
 // Screenshot represents the aggregate data for a single capture.
@@ -51,4 +131,29 @@ type Screenshot struct {
 	ActiveWindow ActiveWindow    `json:"active_window"`
 	Workspace    Workspace       `json:"workspace"`
 	Clients      []Client        `json:"clients"`
+	Redactions   []Redaction     `json:"redactions,omitempty"`
+	Revisions    []Revision      `json:"revisions,omitempty"`
+	SessionID    int64           `json:"session_id,omitempty"`
+	Session      string          `json:"session,omitempty"`
+	Albums       []string        `json:"albums,omitempty"`
+	Shares       []Share         `json:"shares,omitempty"`
+	MimeType     string          `json:"mime_type,omitempty"`
+	Size         int64           `json:"size,omitempty"` // Bytes, before vault encryption
+	Width        int             `json:"width,omitempty"`
+	Height       int             `json:"height,omitempty"`
+	Origin       string          `json:"origin,omitempty"`     // capture or clipboard
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"` // Set for ephemeral entries
+	AppRule      string          `json:"app_rule,omitempty"`   // App rule that matched
+
+	// Recordings share the model with stills. EndWindow is the focused
+	// window when the recording stopped.
+	MediaType  string        `json:"media_type,omitempty"`
+	Duration   float64       `json:"duration,omitempty"` // Seconds
+	PosterPath string        `json:"poster_path,omitempty"`
+	EndWindow  *ActiveWindow `json:"end_window,omitempty"`
+
+	// Region and Scale describe what the captured image covers. They are
+	// only known at capture time and are not persisted.
+	Region *Geometry `json:"region,omitempty"`
+	Scale  float64   `json:"scale,omitempty"`
 }